@@ -19,7 +19,7 @@ type AIScript struct {
 func MakeAIHandler() AIHandler {
 	l := lua.NewState()
 	l.OpenLibs()
-	return AIHandler{l: l, scripts: make([]*AIScript, 1)}
+	return AIHandler{l: l, scripts: make([]*AIScript, 0)}
 }
 
 func (state *AIHandler) Destroy() {
@@ -0,0 +1,29 @@
+package ai
+
+import (
+	"reflect"
+
+	"github.com/laranc/monorepo/engine/ecs"
+)
+
+// Plugin stores an AIHandler resource, runs its scripts every frame and
+// closes its Lua state on shutdown.
+type Plugin struct{}
+
+func (Plugin) Build(world *ecs.ECS) {
+	ecs.InsertResource(world, MakeAIHandler())
+	world.RegisterSystem(func(world *ecs.ECS, commands *ecs.Commands) {
+		handler, err := ecs.ResourceMut[AIHandler](world, commands)
+		if err != nil {
+			return
+		}
+		handler.Update()
+	}, ecs.StageUpdate).Label("ai").Writes(reflect.TypeFor[AIHandler]())
+	world.RegisterSystem(func(world *ecs.ECS, commands *ecs.Commands) {
+		handler, err := ecs.ResourceMut[AIHandler](world, commands)
+		if err != nil {
+			return
+		}
+		handler.Destroy()
+	}, ecs.StageShutdown).Writes(reflect.TypeFor[AIHandler]())
+}
@@ -0,0 +1,33 @@
+package audio
+
+import (
+	"github.com/laranc/monorepo/engine/ecs"
+	"github.com/veandco/go-sdl2/mix"
+)
+
+// Plugin opens the audio device on startup and closes it on shutdown.
+type Plugin struct {
+	Rate      int
+	Format    uint16
+	Channels  int
+	ChunkSize int
+}
+
+// MakePlugin returns a Plugin using mix's default device settings.
+func MakePlugin() Plugin {
+	return Plugin{
+		Rate:      mix.DEFAULT_FREQUENCY,
+		Format:    mix.DEFAULT_FORMAT,
+		Channels:  mix.DEFAULT_CHANNELS,
+		ChunkSize: mix.DEFAULT_CHUNKSIZE,
+	}
+}
+
+func (p Plugin) Build(world *ecs.ECS) {
+	world.RegisterSystem(func(world *ecs.ECS, commands *ecs.Commands) {
+		Init(p.Rate, p.Format, p.Channels, p.ChunkSize)
+	}, ecs.StageStartup).Label("audio")
+	world.RegisterSystem(func(world *ecs.ECS, commands *ecs.Commands) {
+		mix.CloseAudio()
+	}, ecs.StageShutdown)
+}
@@ -0,0 +1,23 @@
+package defaults
+
+import (
+	"github.com/laranc/monorepo/engine/ai"
+	"github.com/laranc/monorepo/engine/audio"
+	"github.com/laranc/monorepo/engine/ecs"
+	"github.com/laranc/monorepo/engine/global"
+	"github.com/laranc/monorepo/engine/graphics2d"
+	"github.com/laranc/monorepo/engine/physics2d"
+)
+
+// Plugins returns the engine's default plugins, for ECS.RegisterDefaults.
+// A game that doesn't want one of them leaves it out with Without, or passes
+// it to RegisterDefaults as an exclusion.
+func Plugins() ecs.PluginSet {
+	return ecs.PluginSet{
+		global.Plugin{},
+		physics2d.Plugin{},
+		graphics2d.AnimationPlugin{},
+		ai.Plugin{},
+		audio.MakePlugin(),
+	}
+}
@@ -0,0 +1,233 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type archetype struct {
+	id       int
+	key      string
+	types    []reflect.Type
+	columns  map[reflect.Type]int
+	entities []Entity
+	data     [][]Component
+	added    [][]uint64
+	changed  [][]uint64
+	add      map[reflect.Type]*archetype
+	remove   map[reflect.Type]*archetype
+}
+
+type entityLocation struct {
+	archetype *archetype
+	row       int
+}
+
+func newArchetype(id int, key string, types []reflect.Type) *archetype {
+	columns := make(map[reflect.Type]int, len(types))
+	for i, t := range types {
+		columns[t] = i
+	}
+	return &archetype{
+		id:       id,
+		key:      key,
+		types:    types,
+		columns:  columns,
+		entities: make([]Entity, 0),
+		data:     make([][]Component, len(types)),
+		added:    make([][]uint64, len(types)),
+		changed:  make([][]uint64, len(types)),
+		add:      make(map[reflect.Type]*archetype),
+		remove:   make(map[reflect.Type]*archetype),
+	}
+}
+
+func (a *archetype) has(t reflect.Type) bool {
+	_, found := a.columns[t]
+	return found
+}
+
+func (a *archetype) matches(with []reflect.Type, without []reflect.Type) bool {
+	for _, w := range with {
+		if !a.has(w) {
+			return false
+		}
+	}
+	for _, w := range without {
+		if a.has(w) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *archetype) push(entity Entity) int {
+	a.entities = append(a.entities, entity)
+	for i := range a.data {
+		a.data[i] = append(a.data[i], nil)
+		a.added[i] = append(a.added[i], 0)
+		a.changed[i] = append(a.changed[i], 0)
+	}
+	return len(a.entities) - 1
+}
+
+// swapRemove removes the row by moving the last row into its place, and
+// returns the entity that was moved (if any).
+func (a *archetype) swapRemove(row int) (Entity, bool) {
+	last := len(a.entities) - 1
+	moved := row != last
+	if moved {
+		a.entities[row] = a.entities[last]
+		for i := range a.data {
+			a.data[i][row] = a.data[i][last]
+			a.added[i][row] = a.added[i][last]
+			a.changed[i][row] = a.changed[i][last]
+		}
+	}
+	a.entities = a.entities[:last]
+	for i := range a.data {
+		a.data[i][last] = nil
+		a.data[i] = a.data[i][:last]
+		a.added[i] = a.added[i][:last]
+		a.changed[i] = a.changed[i][:last]
+	}
+	if moved {
+		return a.entities[row], true
+	}
+	return 0, false
+}
+
+func (a *archetype) get(row int, t reflect.Type) Component {
+	return a.data[a.columns[t]][row]
+}
+
+func (a *archetype) set(row int, t reflect.Type, component Component, tick uint64, added bool) {
+	column := a.columns[t]
+	a.data[column][row] = component
+	a.changed[column][row] = tick
+	if added {
+		a.added[column][row] = tick
+	}
+}
+
+func (a *archetype) components(row int) []Component {
+	result := make([]Component, len(a.data))
+	for i := range a.data {
+		result[i] = a.data[i][row]
+	}
+	return result
+}
+
+// ### ARCHETYPE GRAPH ###
+
+func (ecs *ECS) componentID(t reflect.Type) int {
+	id, found := ecs.componentIDs[t]
+	if !found {
+		id = len(ecs.componentIDs)
+		ecs.componentIDs[t] = id
+	}
+	return id
+}
+
+func (ecs *ECS) archetypeKey(types []reflect.Type) string {
+	var builder strings.Builder
+	for i, t := range types {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(strconv.Itoa(ecs.componentID(t)))
+	}
+	return builder.String()
+}
+
+func (ecs *ECS) sortTypes(types []reflect.Type) {
+	slices.SortFunc(types, func(a, b reflect.Type) int {
+		return ecs.componentID(a) - ecs.componentID(b)
+	})
+}
+
+func (ecs *ECS) getArchetype(types []reflect.Type) *archetype {
+	key := ecs.archetypeKey(types)
+	if a, found := ecs.archetypeIndex[key]; found {
+		return a
+	}
+	a := newArchetype(len(ecs.archetypes), key, types)
+	ecs.archetypes = append(ecs.archetypes, a)
+	ecs.archetypeIndex[key] = a
+	for _, t := range types {
+		ecs.componentIndex[t] = append(ecs.componentIndex[t], a)
+	}
+	return a
+}
+
+func (ecs *ECS) archetypeWith(from *archetype, t reflect.Type) *archetype {
+	if to, found := from.add[t]; found {
+		return to
+	}
+	types := make([]reflect.Type, len(from.types), len(from.types)+1)
+	copy(types, from.types)
+	types = append(types, t)
+	ecs.sortTypes(types)
+	to := ecs.getArchetype(types)
+	from.add[t] = to
+	to.remove[t] = from
+	return to
+}
+
+func (ecs *ECS) archetypeWithout(from *archetype, t reflect.Type) *archetype {
+	if to, found := from.remove[t]; found {
+		return to
+	}
+	types := make([]reflect.Type, 0, len(from.types)-1)
+	for _, ft := range from.types {
+		if ft != t {
+			types = append(types, ft)
+		}
+	}
+	to := ecs.getArchetype(types)
+	from.remove[t] = to
+	to.add[t] = from
+	return to
+}
+
+// moveEntity moves an entity's row to another archetype, carrying over every
+// component the two archetypes share.
+func (ecs *ECS) moveEntity(entity Entity, location entityLocation, to *archetype) entityLocation {
+	from := location.archetype
+	row := to.push(entity)
+	for i, t := range from.types {
+		if column, found := to.columns[t]; found {
+			to.data[column][row] = from.data[i][location.row]
+			to.added[column][row] = from.added[i][location.row]
+			to.changed[column][row] = from.changed[i][location.row]
+		}
+	}
+	ecs.removeRow(location)
+	newLocation := entityLocation{archetype: to, row: row}
+	ecs.setLocation(entity, newLocation)
+	return newLocation
+}
+
+func (ecs *ECS) removeRow(location entityLocation) {
+	if moved, ok := location.archetype.swapRemove(location.row); ok {
+		ecs.setLocation(moved, location)
+	}
+}
+
+// candidateArchetypes returns the smallest set of archetypes that could
+// contain every type in with.
+func (ecs *ECS) candidateArchetypes(with []reflect.Type) []*archetype {
+	if len(with) == 0 {
+		return ecs.archetypes
+	}
+	var result []*archetype
+	for i, w := range with {
+		archetypes := ecs.componentIndex[w]
+		if i == 0 || len(archetypes) < len(result) {
+			result = archetypes
+		}
+	}
+	return result
+}
@@ -0,0 +1,81 @@
+package ecs
+
+import (
+	"math"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultFixedHertz = 60
+	maxFixedSteps     = 8
+)
+
+type Time struct {
+	Delta      time.Duration
+	FixedDelta time.Duration
+	Elapsed    time.Duration
+	Frame      uint64
+}
+
+type clock struct {
+	time        Time
+	last        time.Time
+	accumulator time.Duration
+	pending     atomic.Int64
+}
+
+func (*Time) Type() reflect.Type {
+	return reflect.TypeOf(Time{})
+}
+
+func makeClock(hertz float64) clock {
+	return clock{
+		time: Time{FixedDelta: time.Duration(float64(time.Second) / hertz)},
+		last: time.Now(),
+	}
+}
+
+func (c *clock) reset() {
+	c.last = time.Now()
+	c.accumulator = 0
+}
+
+// tick advances the clock by one frame and returns how many fixed steps are
+// due, capped so a long frame can't snowball into ever longer ones.
+func (c *clock) tick() int {
+	if pending := c.pending.Swap(0); pending > 0 {
+		c.time.FixedDelta = time.Duration(pending)
+	}
+	now := time.Now()
+	c.time.Delta = now.Sub(c.last)
+	c.time.Elapsed += c.time.Delta
+	c.time.Frame++
+	c.last = now
+	c.accumulator += c.time.Delta
+	steps := 0
+	for c.accumulator >= c.time.FixedDelta && steps < maxFixedSteps {
+		c.accumulator -= c.time.FixedDelta
+		steps++
+	}
+	if steps == maxFixedSteps {
+		c.accumulator = 0
+	}
+	return steps
+}
+
+// SetFixedHertz sets how often the fixed update stage runs, starting from
+// the next frame so a running frame keeps a consistent FixedDelta. It
+// returns false, changing nothing, if hertz isn't a usable positive rate.
+func (ecs *ECS) SetFixedHertz(hertz float64) bool {
+	if hertz <= 0 || math.IsNaN(hertz) || math.IsInf(hertz, 0) {
+		return false
+	}
+	delta := time.Duration(float64(time.Second) / hertz)
+	if delta <= 0 {
+		return false
+	}
+	ecs.clock.pending.Store(int64(delta))
+	return true
+}
@@ -0,0 +1,138 @@
+package ecs
+
+import (
+	"reflect"
+	"sync"
+)
+
+type commandKind uint8
+
+const (
+	commandSpawn commandKind = iota
+	commandDestroy
+	commandDestroyRecursive
+	commandAdd
+	commandRemove
+)
+
+type command struct {
+	kind          commandKind
+	entity        Entity
+	components    []Component
+	componentType reflect.Type
+}
+
+type Commands struct {
+	ecs      *ECS
+	commands []command
+	system   *SystemConfig
+	mutex    sync.Mutex
+}
+
+func NewCommands(ecs *ECS) *Commands {
+	return &Commands{ecs: ecs, commands: make([]command, 0)}
+}
+
+// ### RECORDING ###
+
+// Spawn reserves an entity straight away, so later commands can refer to it,
+// and creates it with components when the commands are applied.
+func (c *Commands) Spawn(components ...Component) Entity {
+	c.ecs.mutex.Lock()
+	entity := c.ecs.allocateEntity()
+	c.ecs.mutex.Unlock()
+	c.push(command{kind: commandSpawn, entity: entity, components: components})
+	return entity
+}
+
+func (c *Commands) DestroyEntity(entity Entity) {
+	c.push(command{kind: commandDestroy, entity: entity})
+}
+
+func (c *Commands) DestroyRecursive(entity Entity) {
+	c.push(command{kind: commandDestroyRecursive, entity: entity})
+}
+
+func (c *Commands) AddComponent(entity Entity, component Component) {
+	c.push(command{kind: commandAdd, entity: entity, components: []Component{component}})
+}
+
+func (c *Commands) RemoveComponent(entity Entity, component reflect.Type) {
+	c.push(command{kind: commandRemove, entity: entity, componentType: component})
+}
+
+func (c *Commands) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.commands)
+}
+
+// since is the change tick the system handed these commands last ran at.
+func (c *Commands) since() uint64 {
+	if c == nil || c.system == nil {
+		return 0
+	}
+	return c.system.lastRun
+}
+
+func (c *Commands) push(cmd command) {
+	c.mutex.Lock()
+	c.commands = append(c.commands, cmd)
+	c.mutex.Unlock()
+}
+
+// ### APPLICATION ###
+
+func (ecs *ECS) ApplyCommands(c *Commands) {
+	c.mutex.Lock()
+	commands := c.commands
+	c.commands = make([]command, 0)
+	c.mutex.Unlock()
+	for _, cmd := range commands {
+		switch cmd.kind {
+		case commandSpawn:
+			if !ecs.spawnReserved(cmd.entity) {
+				continue
+			}
+			for _, component := range cmd.components {
+				ecs.AddComponent(cmd.entity, component)
+			}
+		case commandDestroy:
+			ecs.DestroyEntity(cmd.entity)
+		case commandDestroyRecursive:
+			ecs.DestroyRecursive(cmd.entity)
+		case commandAdd:
+			ecs.AddComponent(cmd.entity, cmd.components[0])
+		case commandRemove:
+			ecs.RemoveComponent(cmd.entity, cmd.componentType)
+		}
+	}
+}
+
+// discardCommands drops the commands without applying them, releasing the
+// entities their spawns reserved.
+func (ecs *ECS) discardCommands(c *Commands) {
+	c.mutex.Lock()
+	commands := c.commands
+	c.commands = make([]command, 0)
+	c.mutex.Unlock()
+	ecs.mutex.Lock()
+	for _, cmd := range commands {
+		if cmd.kind == commandSpawn && ecs.isReserved(cmd.entity) {
+			ecs.freeEntity(cmd.entity)
+		}
+	}
+	ecs.mutex.Unlock()
+}
+
+func (ecs *ECS) spawnReserved(entity Entity) bool {
+	ecs.mutex.Lock()
+	defer ecs.mutex.Unlock()
+	if !ecs.isReserved(entity) {
+		return false
+	}
+	ecs.structuralChanges.Add(1)
+	empty := ecs.archetypes[0]
+	ecs.setLocation(entity, entityLocation{archetype: empty, row: empty.push(entity)})
+	return true
+}
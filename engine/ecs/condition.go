@@ -0,0 +1,112 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+)
+
+// Condition decides whether a system runs. Conditions are checked before
+// each batch is handed to the workers, so skipped systems take no part in
+// thread scheduling.
+type Condition func(ecs *ECS) bool
+
+// RunIf adds a condition to the system. A system with several conditions
+// runs only when all of them hold.
+func (c *SystemConfig) RunIf(conditions ...Condition) *SystemConfig {
+	c.conditions = append(c.conditions, conditions...)
+	return c
+}
+
+// shouldRun turns a panicking condition into a PanicError, as runSystem
+// does for the system itself.
+func (c *SystemConfig) shouldRun() (run bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			run, err = false, fmt.Errorf("run condition: %w", &PanicError{Value: r, Stack: debug.Stack()})
+		}
+	}()
+	for _, condition := range c.conditions {
+		if !condition(c.ecs) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// runnable returns the systems in the batch whose conditions hold. Systems
+// whose conditions fail are skipped and handled by their failure policy.
+func (ecs *ECS) runnable(stage string, b batch) (batch, error) {
+	result := make(batch, 0, len(b))
+	failed := make(batch, 0)
+	errs := make([]error, 0)
+	for _, system := range b {
+		if system.disabled {
+			continue
+		}
+		run, err := system.shouldRun()
+		if err != nil {
+			failed = append(failed, system)
+			errs = append(errs, err)
+		} else if run {
+			result = append(result, system)
+		}
+	}
+	return result, ecs.handleFailures(stage, failed, nil, errs)
+}
+
+// ### CONDITIONS ###
+
+func ResourceExists[T any]() Condition {
+	t := reflect.TypeFor[T]()
+	return func(ecs *ECS) bool {
+		ecs.resourceMutex.RLock()
+		defer ecs.resourceMutex.RUnlock()
+		_, found := ecs.resources[t]
+		return found
+	}
+}
+
+// ResourceMatches holds when the resource of type T exists and predicate
+// accepts it.
+func ResourceMatches[T any](predicate func(resource *T) bool) Condition {
+	return func(ecs *ECS) bool {
+		resource, err := ResourceOf[T](ecs, nil)
+		return err == nil && predicate(resource)
+	}
+}
+
+// EveryNFrames holds on every nth frame, counted by Time.Frame.
+func EveryNFrames(n uint64) Condition {
+	return func(ecs *ECS) bool {
+		return n > 0 && ecs.clock.time.Frame%n == 0
+	}
+}
+
+func Not(condition Condition) Condition {
+	return func(ecs *ECS) bool {
+		return !condition(ecs)
+	}
+}
+
+func And(conditions ...Condition) Condition {
+	return func(ecs *ECS) bool {
+		for _, condition := range conditions {
+			if !condition(ecs) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func Or(conditions ...Condition) Condition {
+	return func(ecs *ECS) bool {
+		for _, condition := range conditions {
+			if condition(ecs) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -14,7 +14,11 @@ type Component interface {
 	Type() reflect.Type
 }
 
-type System func(ecs *ECS)
+type System func(ecs *ECS, commands *Commands)
+
+// FallibleSystem is a system that can fail. Failures are handled according
+// to the system's FailurePolicy.
+type FallibleSystem func(ecs *ECS, commands *Commands) error
 
 type Resource interface {
 	Type() reflect.Type
@@ -23,185 +27,344 @@ type Resource interface {
 const (
 	StageUpdate = iota
 	StageStartup
+	StagePreUpdate
+	StageFixedUpdate
+	StagePostUpdate
+	StageRender
+	StageShutdown
 	stageNum
 )
 
 type ECS struct {
-	nextEntity atomic.Uint64
-	components map[Entity][]Component
-	resources  map[reflect.Type]Resource
-	systems    [stageNum][]System
-	mutex      sync.RWMutex
+	nextEntity        atomic.Uint64
+	changeTick        atomic.Uint64
+	structuralChanges atomic.Uint64
+	entities          []entityRecord
+	freeEntities      []uint32
+	archetypes        []*archetype
+	archetypeIndex    map[string]*archetype
+	componentIndex    map[reflect.Type][]*archetype
+	componentIDs      map[reflect.Type]int
+	resources         map[reflect.Type]any
+	shared            map[reflect.Type]bool
+	hooks             map[reflect.Type]*hookSet
+	restoreHooks      []func(ecs *ECS)
+	componentNames    *registry
+	resourceNames     *registry
+	prefabs           map[string]*prefab
+	pairSources       map[reflect.Type]map[Entity][]Entity
+	stages            []*stageSchedule
+	startupStages     []uint
+	frameStages       []uint
+	shutdownStages    []uint
+	stateMachines     []stateMachine
+	plugins           map[reflect.Type]bool
+	failurePolicy     FailurePolicy
+	failures          []*SystemError
+	clock             clock
+	stats             Stats
+	running           atomic.Bool
+	started           bool
+	paused            atomic.Bool
+	mutex             sync.RWMutex
+	frameMutex        sync.Mutex
+	scheduleMutex     sync.Mutex
+	hookMutex         sync.RWMutex
+	prefabMutex       sync.RWMutex
+	resourceMutex     sync.RWMutex
 }
 
 // ### STARTUP FUNCTIONS ###
 
 func NewECS() *ECS {
-	var systems [stageNum][]System
+	stages := make([]*stageSchedule, stageNum)
 	for i := range stageNum {
-		systems[i] = make([]System, 0)
+		stages[i] = newStageSchedule(stageNames[i])
 	}
-	return &ECS{
-		nextEntity: atomic.Uint64{},
-		components: make(map[Entity][]Component),
-		resources:  make(map[reflect.Type]Resource),
-		systems:    systems,
+	ecs := &ECS{
+		nextEntity:     atomic.Uint64{},
+		entities:       make([]entityRecord, 1),
+		freeEntities:   make([]uint32, 0),
+		archetypes:     make([]*archetype, 0),
+		archetypeIndex: make(map[string]*archetype),
+		componentIndex: make(map[reflect.Type][]*archetype),
+		componentIDs:   make(map[reflect.Type]int),
+		resources:      make(map[reflect.Type]any),
+		shared:         make(map[reflect.Type]bool),
+		hooks:          make(map[reflect.Type]*hookSet),
+		componentNames: newRegistry(),
+		resourceNames:  newRegistry(),
+		prefabs:        make(map[string]*prefab),
+		pairSources:    make(map[reflect.Type]map[Entity][]Entity),
+		plugins:        make(map[reflect.Type]bool),
+		failurePolicy:  FailureLog,
+		failures:       make([]*SystemError, 0),
+		stages:         stages,
+		startupStages:  []uint{StageStartup},
+		frameStages:    []uint{StagePreUpdate, StageFixedUpdate, StageUpdate, StagePostUpdate, StageRender},
+		shutdownStages: []uint{StageShutdown},
+		clock:          makeClock(defaultFixedHertz),
 	}
+	ecs.getArchetype(nil)
+	ecs.RegisterResource(&ecs.clock.time)
+	ecs.RegisterResource(&ecs.stats)
+	ecs.registerHierarchyHooks()
+	return ecs
 }
 
 func (ecs *ECS) RegisterResource(resource Resource) {
+	ecs.resourceMutex.Lock()
 	ecs.resources[resource.Type()] = resource
+	delete(ecs.shared, resource.Type())
+	ecs.resourceMutex.Unlock()
+}
+
+func (ecs *ECS) RegisterSystem(system System, stage uint) *SystemConfig {
+	return ecs.registerSystem(newSystemConfig(ecs, infallible(system), systemName(system)), stage)
 }
 
-func (ecs *ECS) RegisterSystem(system System, stage uint) {
-	ecs.systems[stage] = append(ecs.systems[stage], system)
+func (ecs *ECS) RegisterFallibleSystem(system FallibleSystem, stage uint) *SystemConfig {
+	return ecs.registerSystem(newSystemConfig(ecs, system, systemName(system)), stage)
 }
 
-func (ecs *ECS) RegisterDefaults() {
-	// do something
+func (ecs *ECS) registerSystem(config *SystemConfig, stage uint) *SystemConfig {
+	ecs.scheduleMutex.Lock()
+	ecs.stages[stage].systems = append(ecs.stages[stage].systems, config)
+	ecs.stages[stage].plan = nil
+	ecs.scheduleMutex.Unlock()
+	return config
 }
 
 // ### RUNTIME FUNCTIONS ###
 
 func (ecs *ECS) CreateEntity() Entity {
-	entity := Entity(ecs.nextEntity.Add(1))
+	ecs.mutex.Lock()
+	entity := ecs.allocateEntity()
+	ecs.structuralChanges.Add(1)
+	empty := ecs.archetypes[0]
+	ecs.setLocation(entity, entityLocation{archetype: empty, row: empty.push(entity)})
+	ecs.mutex.Unlock()
 	return entity
 }
 
-func (ecs *ECS) AddComponent(entity Entity, component Component) {
+func (ecs *ECS) DestroyEntity(entity Entity) bool {
 	ecs.mutex.Lock()
-	ecs.components[entity] = append(ecs.components[entity], component)
+	location, found := ecs.location(entity)
+	if !found {
+		ecs.mutex.Unlock()
+		return false
+	}
+	components := location.archetype.components(location.row)
+	for _, component := range components {
+		ecs.unindexPair(entity, component)
+	}
+	incoming := ecs.incomingPairs(entity)
+	ecs.structuralChanges.Add(1)
+	ecs.removeRow(location)
+	ecs.freeEntity(entity)
 	ecs.mutex.Unlock()
+	for _, component := range components {
+		ecs.runHooks(hookRemove, entity, component)
+	}
+	for t, sources := range incoming {
+		for _, source := range sources {
+			ecs.removePairTarget(t, source, entity)
+		}
+	}
+	return true
 }
 
-func (ecs *ECS) ComponentQuery(component reflect.Type, with []reflect.Type, without []reflect.Type) ([]Component, bool) {
-	result := make([]Component, 0)
+func (ecs *ECS) IsAlive(entity Entity) bool {
 	ecs.mutex.RLock()
-	for _, components := range ecs.components {
-		hasComponent := false
-		var queriedComponent Component
-		for _, c := range components {
-			if c.Type() == component {
-				hasComponent = true
-				queriedComponent = c
-				break
-			}
-		}
-		if !hasComponent {
-			continue
-		}
-		hasWith := true
-		for _, w := range with {
-			foundWith := false
-			for _, c := range components {
-				if c.Type() == w {
-					foundWith = true
-					break
-				}
-			}
-			if !foundWith {
-				hasWith = false
-				break
-			}
-		}
-		if !hasWith {
-			continue
-		}
-		hasWithout := false
-		for _, w := range without {
-			for _, c := range components {
-				if c.Type() == w {
-					hasWithout = true
-					break
-				}
-			}
-			if hasWithout {
-				break
-			}
-		}
-		if hasWithout {
-			continue
-		}
-		result = append(result, queriedComponent)
+	defer ecs.mutex.RUnlock()
+	return ecs.isAlive(entity)
+}
+
+func (ecs *ECS) AddComponent(entity Entity, component Component) {
+	t := component.Type()
+	ecs.mutex.Lock()
+	location, found := ecs.location(entity)
+	if !found {
+		ecs.mutex.Unlock()
+		return
+	}
+	added := !location.archetype.has(t)
+	var old Component
+	if added {
+		ecs.structuralChanges.Add(1)
+		location = ecs.moveEntity(entity, location, ecs.archetypeWith(location.archetype, t))
+	} else {
+		old = location.archetype.get(location.row, t)
+	}
+	location.archetype.set(location.row, t, component, ecs.changeTick.Add(1), added)
+	if old != nil {
+		ecs.unindexPair(entity, old)
 	}
-	ecs.mutex.RUnlock()
-	return result, len(result) > 0
+	ecs.indexPair(entity, component)
+	ecs.mutex.Unlock()
+	if added {
+		ecs.runHooks(hookAdd, entity, component)
+	} else {
+		ecs.runHooks(hookReplace, entity, old)
+	}
+}
+
+func (ecs *ECS) RemoveComponent(entity Entity, component reflect.Type) bool {
+	ecs.mutex.Lock()
+	location, found := ecs.location(entity)
+	if !found || !location.archetype.has(component) {
+		ecs.mutex.Unlock()
+		return false
+	}
+	old := location.archetype.get(location.row, component)
+	ecs.unindexPair(entity, old)
+	ecs.structuralChanges.Add(1)
+	ecs.moveEntity(entity, location, ecs.archetypeWithout(location.archetype, component))
+	ecs.mutex.Unlock()
+	ecs.runHooks(hookRemove, entity, old)
+	return true
+}
+
+func (ecs *ECS) ReplaceComponent(entity Entity, component Component) bool {
+	t := component.Type()
+	ecs.mutex.Lock()
+	location, found := ecs.location(entity)
+	if !found || !location.archetype.has(t) {
+		ecs.mutex.Unlock()
+		return false
+	}
+	old := location.archetype.get(location.row, t)
+	location.archetype.set(location.row, t, component, ecs.changeTick.Add(1), false)
+	ecs.unindexPair(entity, old)
+	ecs.indexPair(entity, component)
+	ecs.mutex.Unlock()
+	ecs.runHooks(hookReplace, entity, old)
+	return true
+}
+
+func (ecs *ECS) MarkChanged(entity Entity, component reflect.Type) bool {
+	ecs.mutex.Lock()
+	defer ecs.mutex.Unlock()
+	location, found := ecs.location(entity)
+	if !found || !location.archetype.has(component) {
+		return false
+	}
+	a := location.archetype
+	a.changed[a.columns[component]][location.row] = ecs.changeTick.Add(1)
+	return true
+}
+
+func (ecs *ECS) HasComponent(entity Entity, component reflect.Type) bool {
+	ecs.mutex.RLock()
+	defer ecs.mutex.RUnlock()
+	location, found := ecs.location(entity)
+	return found && location.archetype.has(component)
+}
+
+func (ecs *ECS) ComponentQuery(component reflect.Type, with []reflect.Type, without []reflect.Type) ([]Component, bool) {
+	return ecs.FilteredComponentQuery(nil, component, with, without)
 }
 
 func (ecs *ECS) GetComponents(entity Entity) ([]Component, bool) {
 	ecs.mutex.RLock()
-	components, found := ecs.components[entity]
-	ecs.mutex.RUnlock()
-	return components, found
+	defer ecs.mutex.RUnlock()
+	location, found := ecs.location(entity)
+	if !found {
+		return nil, false
+	}
+	return location.archetype.components(location.row), true
 }
 
 func (ecs *ECS) GetResource(r reflect.Type) (Resource, bool) {
-	ecs.mutex.RLock()
-	resource, found := ecs.resources[r]
-	ecs.mutex.RUnlock()
-	return resource, found
+	ecs.resourceMutex.RLock()
+	value, found := ecs.resources[r]
+	ecs.resourceMutex.RUnlock()
+	if !found {
+		return nil, false
+	}
+	resource, ok := value.(Resource)
+	return resource, ok
 }
 
 func (ecs *ECS) EntityQuery(with []reflect.Type, without []reflect.Type) ([]Entity, bool) {
-	result := make([]Entity, 0)
-	ecs.mutex.RLock()
-	for entity, components := range ecs.components {
-		entityComponents := make(map[reflect.Type]bool)
-		for _, c := range components {
-			entityComponents[c.Type()] = true
-		}
-		hasWith := true
-		for _, w := range with {
-			if !entityComponents[w] {
-				hasWith = false
-				break
-			}
-		}
-		if !hasWith {
-			continue
-		}
-		hasWithout := false
-		for _, w := range without {
-			if !entityComponents[w] {
-				hasWithout = true
-				break
-			}
-		}
-		if hasWithout {
-			continue
-		}
-		result = append(result, entity)
-	}
-	ecs.mutex.RUnlock()
-	return result, len(result) > 0
+	return ecs.FilteredEntityQuery(nil, with, without)
 }
 
 // ### SYSTEM FUNCTIONS ###
 
-func (ecs *ECS) Start() {
-	for _, system := range ecs.systems[StageStartup] {
-		go system(ecs)
+func (ecs *ECS) Start() error {
+	if ecs.started {
+		return nil
+	}
+	if err := ecs.Validate(); err != nil {
+		return err
+	}
+	ecs.started = true
+	for _, stage := range ecs.startupStages {
+		if err := ecs.runStage(stage, 0); err != nil {
+			return err
+		}
 	}
+	ecs.clock.reset()
+	return nil
 }
 
-func (ecs *ECS) ExecuteSystems(threads int) {
-	if threads == 0 {
-		threads = len(ecs.systems[StageUpdate])
+// ExecuteSystems runs one frame. While paused only the render stage runs,
+// and the clock stands still.
+func (ecs *ECS) ExecuteSystems(threads int) error {
+	ecs.frameMutex.Lock()
+	defer ecs.frameMutex.Unlock()
+	if err := ecs.Start(); err != nil {
+		return err
 	}
-	systems := make(chan System, len(ecs.systems[StageUpdate]))
-	var wg sync.WaitGroup
-	for range threads {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for system := range systems {
-				system(ecs)
+	if ecs.paused.Load() {
+		ecs.clock.reset()
+		return ecs.runStage(StageRender, 1)
+	}
+	if err := ecs.applyTransitions(); err != nil {
+		return err
+	}
+	steps := ecs.clock.tick()
+	for _, stage := range ecs.frameStages {
+		var err error
+		switch stage {
+		case StageFixedUpdate:
+			for range steps {
+				if err = ecs.runStage(stage, threads); err != nil {
+					break
+				}
 			}
-		}()
+		case StageRender:
+			err = ecs.runStage(stage, 1)
+		default:
+			err = ecs.runStage(stage, threads)
+		}
+		if err != nil {
+			return err
+		}
 	}
-	for _, s := range ecs.systems[StageUpdate] {
-		systems <- s
+	ecs.swapEvents()
+	ecs.collectStats()
+	return nil
+}
+
+func (ecs *ECS) Pause() {
+	ecs.paused.Store(true)
+}
+
+func (ecs *ECS) Resume() {
+	ecs.paused.Store(false)
+}
+
+func (ecs *ECS) Paused() bool {
+	return ecs.paused.Load()
+}
+
+func (ecs *ECS) Shutdown() error {
+	for _, stage := range ecs.shutdownStages {
+		if err := ecs.runStage(stage, 0); err != nil {
+			return err
+		}
 	}
-	close(systems)
-	wg.Wait()
+	return nil
 }
@@ -0,0 +1,82 @@
+package ecs
+
+const (
+	entityIndexBits = 32
+	entityIndexMask = 1<<entityIndexBits - 1
+)
+
+type entityRecord struct {
+	generation uint32
+	alive      bool
+	location   entityLocation
+}
+
+func makeEntity(index, generation uint32) Entity {
+	return Entity(uint64(generation)<<entityIndexBits | uint64(index))
+}
+
+func (e Entity) Index() uint32 {
+	return uint32(e & entityIndexMask)
+}
+
+func (e Entity) Generation() uint32 {
+	return uint32(e >> entityIndexBits)
+}
+
+// ### ALLOCATION ###
+
+func (ecs *ECS) allocateEntity() Entity {
+	if n := len(ecs.freeEntities); n > 0 {
+		index := ecs.freeEntities[n-1]
+		ecs.freeEntities = ecs.freeEntities[:n-1]
+		record := &ecs.entities[index]
+		record.alive = true
+		return makeEntity(index, record.generation)
+	}
+	index := uint32(ecs.nextEntity.Add(1))
+	for uint32(len(ecs.entities)) <= index {
+		ecs.entities = append(ecs.entities, entityRecord{})
+	}
+	ecs.entities[index].alive = true
+	return makeEntity(index, 0)
+}
+
+func (ecs *ECS) freeEntity(entity Entity) {
+	record := &ecs.entities[entity.Index()]
+	record.alive = false
+	record.generation++
+	record.location = entityLocation{}
+	ecs.freeEntities = append(ecs.freeEntities, entity.Index())
+}
+
+func (ecs *ECS) isAlive(entity Entity) bool {
+	index := entity.Index()
+	if index == 0 || index >= uint32(len(ecs.entities)) {
+		return false
+	}
+	record := ecs.entities[index]
+	// a reserved entity isn't alive until its spawn command is applied
+	return record.alive && record.generation == entity.Generation() && record.location.archetype != nil
+}
+
+// isReserved reports whether entity was reserved by Commands.Spawn and hasn't
+// been spawned or released yet.
+func (ecs *ECS) isReserved(entity Entity) bool {
+	index := entity.Index()
+	if index == 0 || index >= uint32(len(ecs.entities)) {
+		return false
+	}
+	record := ecs.entities[index]
+	return record.alive && record.generation == entity.Generation() && record.location.archetype == nil
+}
+
+func (ecs *ECS) location(entity Entity) (entityLocation, bool) {
+	if !ecs.isAlive(entity) {
+		return entityLocation{}, false
+	}
+	return ecs.entities[entity.Index()].location, true
+}
+
+func (ecs *ECS) setLocation(entity Entity, location entityLocation) {
+	ecs.entities[entity.Index()].location = location
+}
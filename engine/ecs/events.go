@@ -0,0 +1,90 @@
+package ecs
+
+import (
+	"reflect"
+	"sync"
+)
+
+type eventInstance[T any] struct {
+	id    uint64
+	event T
+}
+
+// Events is a double-buffered queue of events of type T. Events stay
+// readable for the frame they were sent in and the one after, and each
+// EventReader sees every event exactly once within that window.
+type Events[T any] struct {
+	buffers [2][]eventInstance[T]
+	nextID  uint64
+	mutex   sync.RWMutex
+}
+
+type EventReader[T any] struct {
+	nextID uint64
+}
+
+type eventQueue interface {
+	swap()
+}
+
+func NewEvents[T any]() *Events[T] {
+	return &Events[T]{
+		buffers: [2][]eventInstance[T]{make([]eventInstance[T], 0), make([]eventInstance[T], 0)},
+	}
+}
+
+func NewEventReader[T any]() *EventReader[T] {
+	return &EventReader[T]{}
+}
+
+func (*Events[T]) Type() reflect.Type {
+	return reflect.TypeOf(Events[T]{})
+}
+
+func EventsOf[T any](ecs *ECS) (*Events[T], bool) {
+	resource, found := ecs.GetResource(ResourceType[*Events[T]]())
+	if !found {
+		return nil, false
+	}
+	return ResourceCast[*Events[T]](resource)
+}
+
+// ### SENDING AND READING ###
+
+func (e *Events[T]) Send(event T) {
+	e.mutex.Lock()
+	e.buffers[1] = append(e.buffers[1], eventInstance[T]{id: e.nextID, event: event})
+	e.nextID++
+	e.mutex.Unlock()
+}
+
+func (e *Events[T]) Read(reader *EventReader[T]) []T {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	result := make([]T, 0)
+	for _, buffer := range e.buffers {
+		for _, instance := range buffer {
+			if instance.id >= reader.nextID {
+				result = append(result, instance.event)
+			}
+		}
+	}
+	reader.nextID = e.nextID
+	return result
+}
+
+func (e *Events[T]) swap() {
+	e.mutex.Lock()
+	e.buffers[0], e.buffers[1] = e.buffers[1], e.buffers[0][:0]
+	e.mutex.Unlock()
+}
+
+func (ecs *ECS) swapEvents() {
+	ecs.resourceMutex.RLock()
+	defer ecs.resourceMutex.RUnlock()
+	for _, resource := range ecs.resources {
+		if queue, ok := resource.(eventQueue); ok {
+			queue.swap()
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package ecs
+
+import (
+	"fmt"
+	"slices"
+)
+
+const maxFailures = 256
+
+// FailurePolicy decides what happens when a system returns an error or
+// panics. The commands of a failed system are always discarded.
+type FailurePolicy uint8
+
+const (
+	// FailureDefault uses the world's policy, set with SetFailurePolicy.
+	FailureDefault FailurePolicy = iota
+	// FailureLog prints the error and keeps running the system.
+	FailureLog
+	// FailureDisable prints the error and stops running the system until
+	// it's enabled again.
+	FailureDisable
+	// FailureStop makes ExecuteSystems return the error once the stage's
+	// current batch has finished. The commands of the systems that succeeded
+	// so far in the stage are still applied; later batches don't run.
+	FailureStop
+)
+
+type SystemError struct {
+	Stage string
+	Label string
+	Frame uint64
+	Err   error
+}
+
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *SystemError) Error() string {
+	return fmt.Sprintf("Error in system '%s' in stage %s on frame %d: %s", e.Label, e.Stage, e.Frame, e.Err)
+}
+
+func (e *SystemError) Unwrap() error {
+	return e.Err
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// ### POLICIES ###
+
+func (ecs *ECS) SetFailurePolicy(policy FailurePolicy) {
+	ecs.scheduleMutex.Lock()
+	if policy != FailureDefault {
+		ecs.failurePolicy = policy
+	}
+	ecs.scheduleMutex.Unlock()
+}
+
+func (c *SystemConfig) OnFailure(policy FailurePolicy) *SystemConfig {
+	c.policy = policy
+	return c
+}
+
+// Enable lets a system disabled by FailureDisable run again.
+func (c *SystemConfig) Enable() *SystemConfig {
+	c.disabled = false
+	return c
+}
+
+// Errors returns the most recent system failures, oldest first.
+func (ecs *ECS) Errors() []*SystemError {
+	ecs.scheduleMutex.Lock()
+	defer ecs.scheduleMutex.Unlock()
+	return slices.Clone(ecs.failures)
+}
+
+// ### HANDLING ###
+
+// handleFailures records every failure in the batch and applies each
+// system's policy, returning an error if one of them stops the app. commands
+// is nil for failed run conditions, which have none to discard.
+func (ecs *ECS) handleFailures(stage string, b batch, commands []*Commands, errs []error) error {
+	var stop error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if commands != nil {
+			ecs.discardCommands(commands[i])
+		}
+		failure := &SystemError{Stage: stage, Label: b[i].displayName(), Frame: ecs.clock.time.Frame, Err: err}
+		ecs.scheduleMutex.Lock()
+		ecs.failures = append(ecs.failures, failure)
+		if len(ecs.failures) > maxFailures {
+			ecs.failures = slices.Delete(ecs.failures, 0, len(ecs.failures)-maxFailures)
+		}
+		policy := b[i].policy
+		if policy == FailureDefault {
+			policy = ecs.failurePolicy
+		}
+		ecs.scheduleMutex.Unlock()
+		switch policy {
+		case FailureLog:
+			fmt.Println(failure)
+		case FailureDisable:
+			b[i].disabled = true
+			fmt.Printf("%s; system disabled\n", failure)
+		case FailureStop:
+			if stop == nil {
+				stop = failure
+			}
+		}
+	}
+	return stop
+}
@@ -0,0 +1,141 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Filter narrows a query down by change detection. A filtered query
+// remembers when it last ran, so it belongs to one system: systems sharing
+// it would consume each other's changes.
+type Filter interface {
+	filter() queryFilter
+}
+
+// Added matches components added since the query last ran, or since the
+// calling system last ran for FilteredEntityQuery and FilteredComponentQuery.
+type Added[T Component] struct{}
+
+// Changed matches components added, replaced or marked changed since the
+// query, or the calling system, last ran.
+type Changed[T Component] struct{}
+
+type queryFilter struct {
+	componentType reflect.Type
+	added         bool
+}
+
+func (Added[T]) filter() queryFilter {
+	return queryFilter{componentType: ComponentType[T](), added: true}
+}
+
+func (Changed[T]) filter() queryFilter {
+	return queryFilter{componentType: ComponentType[T](), added: false}
+}
+
+func (f queryFilter) matches(a *archetype, row int, since uint64) bool {
+	column := a.columns[f.componentType]
+	if f.added {
+		return a.added[column][row] > since
+	}
+	return a.changed[column][row] > since
+}
+
+func (q *Query) Filter(filters ...Filter) *Query {
+	q.addFilters(filters)
+	return q
+}
+
+func (q *Query1[A]) Filter(filters ...Filter) *Query1[A] {
+	q.addFilters(filters)
+	return q
+}
+
+func (q *Query2[A, B]) Filter(filters ...Filter) *Query2[A, B] {
+	q.addFilters(filters)
+	return q
+}
+
+func (q *Query3[A, B, C]) Filter(filters ...Filter) *Query3[A, B, C] {
+	q.addFilters(filters)
+	return q
+}
+
+func (q *queryState) addFilters(filters []Filter) {
+	resolved, with := resolveFilters(filters, q.with)
+	q.filters = append(q.filters, resolved...)
+	q.with = with
+	q.invalidate()
+}
+
+// FilteredEntityQuery is EntityQuery narrowed by filters, which compare
+// against the previous run of the system that was handed commands. Without a
+// system's commands every component counts as added and changed.
+func (ecs *ECS) FilteredEntityQuery(commands *Commands, with []reflect.Type, without []reflect.Type, filters ...Filter) ([]Entity, bool) {
+	resolved, with := resolveFilters(filters, with)
+	since := commands.since()
+	result := make([]Entity, 0)
+	ecs.mutex.RLock()
+	for _, a := range ecs.candidateArchetypes(with) {
+		if !a.matches(with, without) {
+			continue
+		}
+		if len(resolved) == 0 {
+			result = append(result, a.entities...)
+			continue
+		}
+		for row, entity := range a.entities {
+			if matchesFilters(resolved, a, row, since) {
+				result = append(result, entity)
+			}
+		}
+	}
+	ecs.mutex.RUnlock()
+	return result, len(result) > 0
+}
+
+func (ecs *ECS) FilteredComponentQuery(commands *Commands, component reflect.Type, with []reflect.Type, without []reflect.Type, filters ...Filter) ([]Component, bool) {
+	resolved, with := resolveFilters(filters, with)
+	since := commands.since()
+	result := make([]Component, 0)
+	ecs.mutex.RLock()
+	for _, a := range ecs.componentIndex[component] {
+		if !a.matches(with, without) {
+			continue
+		}
+		column := a.data[a.columns[component]]
+		if len(resolved) == 0 {
+			result = append(result, column...)
+			continue
+		}
+		for row := range a.entities {
+			if matchesFilters(resolved, a, row, since) {
+				result = append(result, column[row])
+			}
+		}
+	}
+	ecs.mutex.RUnlock()
+	return result, len(result) > 0
+}
+
+// resolveFilters also returns with extended by the filtered types, since a
+// filter only matches entities that have its component.
+func resolveFilters(filters []Filter, with []reflect.Type) ([]queryFilter, []reflect.Type) {
+	resolved := make([]queryFilter, 0, len(filters))
+	with = slices.Clip(with)
+	for _, f := range filters {
+		r := f.filter()
+		resolved = append(resolved, r)
+		with = append(with, r.componentType)
+	}
+	return resolved, with
+}
+
+func matchesFilters(filters []queryFilter, a *archetype, row int, since uint64) bool {
+	for _, f := range filters {
+		if !f.matches(a, row, since) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package ecs
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+type ScheduleError struct {
+	Stage  string
+	Label  string
+	Reason string
+}
+
+func (e *ScheduleError) Error() string {
+	return fmt.Sprintf("Error in stage '%s' at system '%s': %s", e.Stage, e.Label, e.Reason)
+}
+
+var stageNames = [stageNum]string{
+	StageUpdate:      "Update",
+	StageStartup:     "Startup",
+	StagePreUpdate:   "PreUpdate",
+	StageFixedUpdate: "FixedUpdate",
+	StagePostUpdate:  "PostUpdate",
+	StageRender:      "Render",
+	StageShutdown:    "Shutdown",
+}
+
+// ### ORDERING CONSTRAINTS ###
+
+func (c *SystemConfig) Label(label string) *SystemConfig {
+	c.label = label
+	c.ecs.invalidateSchedule()
+	return c
+}
+
+func (c *SystemConfig) Before(labels ...string) *SystemConfig {
+	c.before = append(c.before, labels...)
+	c.ecs.invalidateSchedule()
+	return c
+}
+
+func (c *SystemConfig) After(labels ...string) *SystemConfig {
+	c.after = append(c.after, labels...)
+	c.ecs.invalidateSchedule()
+	return c
+}
+
+func (c *SystemConfig) displayName() string {
+	if c.label != "" {
+		return c.label
+	}
+	return c.name
+}
+
+// ### RESOLUTION ###
+
+// resolve orders the stage's systems topologically, breaking ties by
+// registration order, and records each system's direct predecessors.
+func (s *stageSchedule) resolve() error {
+	labels := make(map[string]*SystemConfig)
+	for _, system := range s.systems {
+		if system.label == "" {
+			continue
+		}
+		if _, found := labels[system.label]; found {
+			return &ScheduleError{Stage: s.name, Label: system.label, Reason: "duplicate label"}
+		}
+		labels[system.label] = system
+	}
+	predecessors := make(map[*SystemConfig][]*SystemConfig)
+	successors := make(map[*SystemConfig][]*SystemConfig)
+	link := func(from, to *SystemConfig) {
+		if !slices.Contains(predecessors[to], from) {
+			predecessors[to] = append(predecessors[to], from)
+			successors[from] = append(successors[from], to)
+		}
+	}
+	for _, system := range s.systems {
+		for _, label := range system.before {
+			other, found := labels[label]
+			if !found {
+				return &ScheduleError{Stage: s.name, Label: system.displayName(), Reason: fmt.Sprintf("unknown label '%s' in Before", label)}
+			}
+			link(system, other)
+		}
+		for _, label := range system.after {
+			other, found := labels[label]
+			if !found {
+				return &ScheduleError{Stage: s.name, Label: system.displayName(), Reason: fmt.Sprintf("unknown label '%s' in After", label)}
+			}
+			link(other, system)
+		}
+	}
+	pending := make(map[*SystemConfig]int, len(s.systems))
+	for _, system := range s.systems {
+		pending[system] = len(predecessors[system])
+	}
+	order := make([]*SystemConfig, 0, len(s.systems))
+	done := make(map[*SystemConfig]bool, len(s.systems))
+	for len(order) < len(s.systems) {
+		var next *SystemConfig
+		for _, system := range s.systems {
+			if !done[system] && pending[system] == 0 {
+				next = system
+				break
+			}
+		}
+		if next == nil {
+			cycle := make([]string, 0)
+			for _, system := range s.systems {
+				if !done[system] {
+					cycle = append(cycle, system.displayName())
+				}
+			}
+			return &ScheduleError{Stage: s.name, Label: cycle[0], Reason: "ordering cycle between " + strings.Join(cycle, ", ")}
+		}
+		done[next] = true
+		order = append(order, next)
+		for _, successor := range successors[next] {
+			pending[successor]--
+		}
+	}
+	s.order = order
+	s.predecessors = predecessors
+	return nil
+}
+
+func (ecs *ECS) Validate() error {
+	ecs.scheduleMutex.Lock()
+	sequences := [][]uint{ecs.startupStages, ecs.frameStages, ecs.shutdownStages}
+	ecs.scheduleMutex.Unlock()
+	for _, sequence := range sequences {
+		for _, stage := range sequence {
+			if _, err := ecs.plan(stage); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ### DOT OUTPUT ###
+
+func (ecs *ECS) WriteDOT(w io.Writer) error {
+	if err := ecs.Validate(); err != nil {
+		return err
+	}
+	ecs.scheduleMutex.Lock()
+	defer ecs.scheduleMutex.Unlock()
+	var builder strings.Builder
+	builder.WriteString("digraph schedule {\n\trankdir=LR;\n")
+	sequences := [][]uint{ecs.startupStages, ecs.frameStages, ecs.shutdownStages}
+	for _, sequence := range sequences {
+		for _, stage := range sequence {
+			s := ecs.stages[stage]
+			fmt.Fprintf(&builder, "\tsubgraph cluster_%d {\n\t\tlabel=%q;\n", stage, s.name)
+			for _, system := range s.order {
+				fmt.Fprintf(&builder, "\t\ts%d_%d [label=%q];\n", stage, slices.Index(s.systems, system), system.displayName())
+			}
+			for _, system := range s.order {
+				to := slices.Index(s.systems, system)
+				for _, predecessor := range s.predecessors[system] {
+					fmt.Fprintf(&builder, "\t\ts%d_%d -> s%d_%d;\n", stage, slices.Index(s.systems, predecessor), stage, to)
+				}
+			}
+			builder.WriteString("\t}\n")
+		}
+	}
+	builder.WriteString("}\n")
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
@@ -0,0 +1,137 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+)
+
+type Parent struct {
+	Entity Entity
+}
+
+type Children struct {
+	Entities []Entity
+}
+
+func (*Parent) Type() reflect.Type {
+	return reflect.TypeOf(Parent{})
+}
+
+func (*Children) Type() reflect.Type {
+	return reflect.TypeOf(Children{})
+}
+
+// ### HIERARCHY FUNCTIONS ###
+
+func (ecs *ECS) SetParent(child, parent Entity) bool {
+	if child == parent || !ecs.IsAlive(child) || !ecs.IsAlive(parent) {
+		return false
+	}
+	if slices.Contains(ecs.Descendants(child), parent) {
+		return false
+	}
+	ecs.AddComponent(child, &Parent{Entity: parent})
+	return true
+}
+
+func (ecs *ECS) RemoveParent(child Entity) bool {
+	return ecs.RemoveComponent(child, ComponentType[*Parent]())
+}
+
+func (ecs *ECS) GetParent(child Entity) (Entity, bool) {
+	parent, found := NewQuery1[*Parent](ecs).Get(child)
+	if !found {
+		return 0, false
+	}
+	return parent.Entity, true
+}
+
+func (ecs *ECS) GetChildren(parent Entity) []Entity {
+	children, found := NewQuery1[*Children](ecs).Get(parent)
+	if !found {
+		return nil
+	}
+	return slices.Clone(children.Entities)
+}
+
+// Descendants returns every entity below the given one, parents before
+// their children.
+func (ecs *ECS) Descendants(entity Entity) []Entity {
+	result := make([]Entity, 0)
+	query := NewQuery1[*Children](ecs)
+	var visit func(Entity)
+	visit = func(e Entity) {
+		children, found := query.Get(e)
+		if !found {
+			return
+		}
+		for _, child := range children.Entities {
+			result = append(result, child)
+			visit(child)
+		}
+	}
+	visit(entity)
+	return result
+}
+
+func (ecs *ECS) DestroyRecursive(entity Entity) bool {
+	descendants := ecs.Descendants(entity)
+	for i := len(descendants) - 1; i >= 0; i-- {
+		ecs.DestroyEntity(descendants[i])
+	}
+	return ecs.DestroyEntity(entity)
+}
+
+// ### MAINTENANCE HOOKS ###
+
+func (ecs *ECS) registerHierarchyHooks() {
+	parentType := ComponentType[*Parent]()
+	ecs.OnAdd(parentType, func(ecs *ECS, entity Entity, component Component) {
+		ecs.addChild(component.(*Parent).Entity, entity)
+	})
+	ecs.OnReplace(parentType, func(ecs *ECS, entity Entity, component Component) {
+		ecs.removeChild(component.(*Parent).Entity, entity)
+		if parent, found := ecs.GetParent(entity); found {
+			ecs.addChild(parent, entity)
+		}
+	})
+	ecs.OnRemove(parentType, func(ecs *ECS, entity Entity, component Component) {
+		ecs.removeChild(component.(*Parent).Entity, entity)
+	})
+	ecs.OnRemove(ComponentType[*Children](), func(ecs *ECS, entity Entity, component Component) {
+		for _, child := range component.(*Children).Entities {
+			if parent, found := ecs.GetParent(child); found && parent == entity {
+				ecs.RemoveParent(child)
+			}
+		}
+	})
+}
+
+func (ecs *ECS) addChild(parent, child Entity) {
+	children, found := NewQuery1[*Children](ecs).Get(parent)
+	if !found {
+		ecs.AddComponent(parent, &Children{Entities: []Entity{child}})
+		return
+	}
+	if !slices.Contains(children.Entities, child) {
+		children.Entities = append(children.Entities, child)
+		ecs.MarkChanged(parent, ComponentType[*Children]())
+	}
+}
+
+func (ecs *ECS) removeChild(parent, child Entity) {
+	children, found := NewQuery1[*Children](ecs).Get(parent)
+	if !found {
+		return
+	}
+	i := slices.Index(children.Entities, child)
+	if i < 0 {
+		return
+	}
+	children.Entities = slices.Delete(children.Entities, i, i+1)
+	if len(children.Entities) == 0 {
+		ecs.RemoveComponent(parent, ComponentType[*Children]())
+	} else {
+		ecs.MarkChanged(parent, ComponentType[*Children]())
+	}
+}
@@ -0,0 +1,65 @@
+package ecs
+
+import "reflect"
+
+// Hook runs after a component of the hooked type is added to, replaced on or
+// removed from an entity. Replace and remove hooks receive the old component.
+// Hooks run synchronously for direct calls and while commands are applied for
+// deferred ones, always outside the world lock.
+type Hook func(ecs *ECS, entity Entity, component Component)
+
+type hookKind uint8
+
+const (
+	hookAdd hookKind = iota
+	hookRemove
+	hookReplace
+	hookNum
+)
+
+type hookSet [hookNum][]Hook
+
+func (ecs *ECS) OnAdd(component reflect.Type, hook Hook) {
+	ecs.registerHook(hookAdd, component, hook)
+}
+
+func (ecs *ECS) OnRemove(component reflect.Type, hook Hook) {
+	ecs.registerHook(hookRemove, component, hook)
+}
+
+func (ecs *ECS) OnReplace(component reflect.Type, hook Hook) {
+	ecs.registerHook(hookReplace, component, hook)
+}
+
+// OnRestore registers a hook that runs after Restore, outside the world
+// lock, so state kept outside the world can be rebuilt from the restored
+// components.
+func (ecs *ECS) OnRestore(hook func(ecs *ECS)) {
+	ecs.hookMutex.Lock()
+	ecs.restoreHooks = append(ecs.restoreHooks, hook)
+	ecs.hookMutex.Unlock()
+}
+
+func (ecs *ECS) registerHook(kind hookKind, component reflect.Type, hook Hook) {
+	ecs.hookMutex.Lock()
+	hooks, found := ecs.hooks[component]
+	if !found {
+		hooks = new(hookSet)
+		ecs.hooks[component] = hooks
+	}
+	hooks[kind] = append(hooks[kind], hook)
+	ecs.hookMutex.Unlock()
+}
+
+func (ecs *ECS) runHooks(kind hookKind, entity Entity, component Component) {
+	ecs.hookMutex.RLock()
+	hooks, found := ecs.hooks[component.Type()]
+	var list []Hook
+	if found {
+		list = hooks[kind]
+	}
+	ecs.hookMutex.RUnlock()
+	for _, hook := range list {
+		hook(ecs, entity, component)
+	}
+}
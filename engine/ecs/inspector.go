@@ -0,0 +1,278 @@
+package ecs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// Inspector serves a live view of a world as JSON over HTTP for debugging.
+// Requests are handled between frames, so they always see a consistent world.
+//
+//	GET  /entities                           every entity and its component names
+//	GET  /entities/{id}                      one entity with its component data
+//	PATCH /entities/{id}/components/{name}   merge a JSON object into a component
+//	GET  /resources                          every resource
+//	GET  /stats                              the Stats resource
+//	GET  /schedule                           the batch plan of every stage
+//	POST /pause, POST /resume                pause or resume the schedule
+type Inspector struct {
+	ecs      *ECS
+	listener net.Listener
+	server   *http.Server
+}
+
+type entitySummary struct {
+	ID         Entity   `json:"id"`
+	Index      uint32   `json:"index"`
+	Generation uint32   `json:"generation"`
+	Components []string `json:"components"`
+}
+
+type entityDetail struct {
+	ID         Entity                     `json:"id"`
+	Index      uint32                     `json:"index"`
+	Generation uint32                     `json:"generation"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+type stagePlan struct {
+	Stage   string     `json:"stage"`
+	Batches [][]string `json:"batches"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// Inspect starts an inspector for the world on addr, such as
+// "localhost:7070". Only loopback addresses are accepted.
+func (ecs *ECS) Inspect(addr string) (*Inspector, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("inspector address '%s' is not a loopback address", addr)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	inspector := &Inspector{ecs: ecs, listener: listener}
+	inspector.server = &http.Server{Handler: inspector.handler()}
+	go inspector.server.Serve(listener)
+	return inspector, nil
+}
+
+func (i *Inspector) Addr() string {
+	return i.listener.Addr().String()
+}
+
+func (i *Inspector) Close() error {
+	return i.server.Close()
+}
+
+func (i *Inspector) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /entities", i.between(i.entities))
+	mux.HandleFunc("GET /entities/{id}", i.between(i.entity))
+	mux.HandleFunc("PATCH /entities/{id}/components/{name}", i.between(i.editComponent))
+	mux.HandleFunc("GET /resources", i.between(i.resources))
+	mux.HandleFunc("GET /stats", i.between(i.stats))
+	mux.HandleFunc("GET /schedule", i.between(i.schedule))
+	mux.HandleFunc("POST /pause", func(w http.ResponseWriter, r *http.Request) {
+		i.ecs.Pause()
+		writeJSON(w, map[string]bool{"paused": true})
+	})
+	mux.HandleFunc("POST /resume", func(w http.ResponseWriter, r *http.Request) {
+		i.ecs.Resume()
+		writeJSON(w, map[string]bool{"paused": false})
+	})
+	return mux
+}
+
+// between wraps a handler so it only runs while no frame is executing.
+func (i *Inspector) between(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		i.ecs.frameMutex.Lock()
+		defer i.ecs.frameMutex.Unlock()
+		handler(w, r)
+	}
+}
+
+// ### HANDLERS ###
+
+func (i *Inspector) entities(w http.ResponseWriter, r *http.Request) {
+	result := make([]entitySummary, 0)
+	i.ecs.mutex.RLock()
+	for _, a := range i.ecs.archetypes {
+		names := make([]string, len(a.types))
+		for j, t := range a.types {
+			names[j] = i.ecs.componentNames.displayName(t)
+		}
+		for _, entity := range a.entities {
+			result = append(result, entitySummary{
+				ID:         entity,
+				Index:      entity.Index(),
+				Generation: entity.Generation(),
+				Components: names,
+			})
+		}
+	}
+	i.ecs.mutex.RUnlock()
+	slices.SortFunc(result, func(a, b entitySummary) int {
+		return int(a.Index) - int(b.Index)
+	})
+	writeJSON(w, result)
+}
+
+func (i *Inspector) entity(w http.ResponseWriter, r *http.Request) {
+	entity, ok := pathEntity(w, r)
+	if !ok {
+		return
+	}
+	i.ecs.mutex.RLock()
+	defer i.ecs.mutex.RUnlock()
+	location, found := i.ecs.location(entity)
+	if !found {
+		http.Error(w, fmt.Sprintf("entity %d not found", entity), http.StatusNotFound)
+		return
+	}
+	detail := entityDetail{
+		ID:         entity,
+		Index:      entity.Index(),
+		Generation: entity.Generation(),
+		Components: make(map[string]json.RawMessage, len(location.archetype.types)),
+	}
+	for _, t := range location.archetype.types {
+		detail.Components[i.ecs.componentNames.displayName(t)] = encodeValue(location.archetype.get(location.row, t))
+	}
+	writeJSON(w, detail)
+}
+
+// editComponent decodes the request body over a copy of the component and
+// swaps it in with ReplaceComponent, so hooks and change detection see it.
+func (i *Inspector) editComponent(w http.ResponseWriter, r *http.Request) {
+	entity, ok := pathEntity(w, r)
+	if !ok {
+		return
+	}
+	name := r.PathValue("name")
+	components, found := i.ecs.GetComponents(entity)
+	if !found {
+		http.Error(w, fmt.Sprintf("entity %d not found", entity), http.StatusNotFound)
+		return
+	}
+	index := slices.IndexFunc(components, func(c Component) bool {
+		return i.ecs.componentNames.displayName(c.Type()) == name
+	})
+	if index < 0 {
+		http.Error(w, fmt.Sprintf("entity %d has no component '%s'", entity, name), http.StatusNotFound)
+		return
+	}
+	edited, err := decodeOnto(components[index], r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	i.ecs.ReplaceComponent(entity, edited)
+	writeJSON(w, encodeValue(edited))
+}
+
+func (i *Inspector) resources(w http.ResponseWriter, r *http.Request) {
+	result := make(map[string]json.RawMessage)
+	i.ecs.resourceMutex.RLock()
+	for t, resource := range i.ecs.resources {
+		result[i.ecs.resourceNames.displayName(t)] = encodeValue(resource)
+	}
+	i.ecs.resourceMutex.RUnlock()
+	writeJSON(w, result)
+}
+
+func (i *Inspector) stats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, &i.ecs.stats)
+}
+
+func (i *Inspector) schedule(w http.ResponseWriter, r *http.Request) {
+	result := make([]stagePlan, 0)
+	for _, sequence := range [][]uint{i.ecs.startupStages, i.ecs.frameStages, i.ecs.shutdownStages} {
+		for _, stage := range sequence {
+			plan := stagePlan{Stage: i.ecs.stages[stage].name}
+			batches, err := i.ecs.Plan(stage)
+			if err != nil {
+				plan.Error = err.Error()
+			}
+			plan.Batches = batches
+			result = append(result, plan)
+		}
+	}
+	writeJSON(w, result)
+}
+
+// ### ENCODING ###
+
+// displayName prefers the name a type was registered under for saving.
+func (r *registry) displayName(t reflect.Type) string {
+	if name, found := r.name(t); found {
+		return name
+	}
+	return t.String()
+}
+
+func pathEntity(w http.ResponseWriter, r *http.Request) (Entity, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid entity '%s'", r.PathValue("id")), http.StatusBadRequest)
+		return 0, false
+	}
+	return Entity(id), true
+}
+
+// decodeOnto decodes the request into a copy of component, so a body that
+// fails halfway never touches the live value. The copy is always made by
+// reflection, since a Cloner's Clone or a NoSnapshot component may share it.
+func decodeOnto(component Component, r *http.Request) (Component, error) {
+	v := reflect.ValueOf(component)
+	pointer := v.Kind() == reflect.Pointer
+	var copied reflect.Value
+	if pointer {
+		copied = reflect.New(v.Type().Elem())
+		if !v.IsNil() {
+			copyValue(copied.Elem(), v.Elem())
+		}
+	} else {
+		copied = reflect.New(v.Type())
+		copyValue(copied.Elem(), v)
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(copied.Interface()); err != nil {
+		return nil, err
+	}
+	if pointer {
+		return copied.Interface().(Component), nil
+	}
+	return copied.Elem().Interface().(Component), nil
+}
+
+// encodeValue marshals value, reporting failures inline so one bad component
+// doesn't hide the rest.
+func encodeValue(value any) json.RawMessage {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	return data
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	data, err := json.MarshalIndent(value, "", "\t")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
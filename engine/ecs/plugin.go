@@ -0,0 +1,76 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Plugin bundles the resources and systems of one engine subsystem.
+type Plugin interface {
+	Build(ecs *ECS)
+}
+
+// PluginFunc lets a plain function be used as a Plugin.
+type PluginFunc func(ecs *ECS)
+
+// PluginSet is an ordered list of plugins, such as the engine's defaults,
+// that a game can adjust before installing it.
+type PluginSet []Plugin
+
+func (f PluginFunc) Build(ecs *ECS) {
+	f(ecs)
+}
+
+// With returns a copy of the set where each plugin replaces the plugin of
+// the same type, or is added if there's none, so a game can swap in a
+// configured plugin of its own.
+func (s PluginSet) With(plugins ...Plugin) PluginSet {
+	set := slices.Clone(s)
+	for _, plugin := range plugins {
+		if i := set.index(plugin); i < 0 {
+			set = append(set, plugin)
+		} else {
+			set[i] = plugin
+		}
+	}
+	return set
+}
+
+// Without returns a copy of the set without the plugins of the same types
+// as plugins.
+func (s PluginSet) Without(plugins ...Plugin) PluginSet {
+	return slices.DeleteFunc(slices.Clone(s), func(p Plugin) bool {
+		return slices.ContainsFunc(plugins, func(excluded Plugin) bool {
+			return reflect.TypeOf(p) == reflect.TypeOf(excluded)
+		})
+	})
+}
+
+func (s PluginSet) index(plugin Plugin) int {
+	return slices.IndexFunc(s, func(p Plugin) bool {
+		return reflect.TypeOf(p) == reflect.TypeOf(plugin)
+	})
+}
+
+// AddPlugins builds each plugin into the world. A plugin type that has
+// already been built is skipped, so plugins can add the plugins they depend
+// on without registering anything twice.
+func (ecs *ECS) AddPlugins(plugins ...Plugin) {
+	for _, plugin := range plugins {
+		t := reflect.TypeOf(plugin)
+		ecs.scheduleMutex.Lock()
+		built := ecs.plugins[t]
+		ecs.plugins[t] = true
+		ecs.scheduleMutex.Unlock()
+		if !built {
+			plugin.Build(ecs)
+		}
+	}
+}
+
+// RegisterDefaults installs a set of default plugins, leaving out those of
+// the same types as exclude. The engine's own set is built by the defaults
+// package, which is the only place that decides what it's made of.
+func (ecs *ECS) RegisterDefaults(defaults PluginSet, exclude ...Plugin) {
+	ecs.AddPlugins(defaults.Without(exclude...)...)
+}
@@ -0,0 +1,336 @@
+package ecs
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+
+	"github.com/laranc/monorepo/engine/config"
+	"github.com/yuin/gopher-lua"
+)
+
+type PrefabError struct {
+	Prefab string
+	Field  string
+	Reason string
+}
+
+func (e *PrefabError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("Error in prefab '%s': %s", e.Prefab, e.Reason)
+	}
+	return fmt.Sprintf("Error in prefab '%s' at '%s': %s", e.Prefab, e.Field, e.Reason)
+}
+
+type prefab struct {
+	extends    string
+	components map[string]map[string]any
+}
+
+// ### LOADING ###
+
+// LoadPrefabs reads a Lua file returning a table of prefabs, each with an
+// optional "extends" parent name and a "components" table keyed by registered
+// component name. Every field is checked against its component type here, so
+// Spawn can't fail on bad data later.
+func (ecs *ECS) LoadPrefabs(path string) error {
+	table, err := config.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	raw := make(map[string]*prefab)
+	var parseErr error
+	table.ForEach(func(key, value lua.LValue) {
+		if parseErr != nil {
+			return
+		}
+		name := key.String()
+		definition, ok := value.(*lua.LTable)
+		if !ok {
+			parseErr = &PrefabError{Prefab: name, Reason: "definition is not a table"}
+			return
+		}
+		raw[name], parseErr = parsePrefab(name, definition)
+	})
+	if parseErr != nil {
+		return parseErr
+	}
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	resolved := make(map[string]*prefab, len(raw))
+	for _, name := range names {
+		p, err := resolvePrefab(name, raw, resolved, nil)
+		if err != nil {
+			return err
+		}
+		if err := ecs.validatePrefab(name, p); err != nil {
+			return err
+		}
+	}
+	ecs.prefabMutex.Lock()
+	for name, p := range resolved {
+		ecs.prefabs[name] = p
+	}
+	ecs.prefabMutex.Unlock()
+	return nil
+}
+
+func parsePrefab(name string, definition *lua.LTable) (*prefab, error) {
+	p := &prefab{components: make(map[string]map[string]any)}
+	if extends, ok := definition.RawGetString("extends").(lua.LString); ok {
+		p.extends = string(extends)
+	}
+	switch components := definition.RawGetString("components").(type) {
+	case *lua.LTable:
+		var err error
+		components.ForEach(func(key, value lua.LValue) {
+			fields, ok := fromLua(value).(map[string]any)
+			if !ok && err == nil {
+				err = &PrefabError{Prefab: name, Field: key.String(), Reason: "component is not a table of fields"}
+			}
+			p.components[key.String()] = fields
+		})
+		if err != nil {
+			return nil, err
+		}
+	case *lua.LNilType:
+	default:
+		return nil, &PrefabError{Prefab: name, Reason: "components is not a table"}
+	}
+	return p, nil
+}
+
+// resolvePrefab flattens a prefab's inheritance chain, letting each child
+// override its parent field by field.
+func resolvePrefab(name string, raw, resolved map[string]*prefab, chain []string) (*prefab, error) {
+	if p, found := resolved[name]; found {
+		return p, nil
+	}
+	if slices.Contains(chain, name) {
+		return nil, &PrefabError{Prefab: name, Reason: fmt.Sprintf("inheritance cycle %v", append(chain, name))}
+	}
+	p, found := raw[name]
+	if !found {
+		return nil, &PrefabError{Prefab: chain[len(chain)-1], Reason: fmt.Sprintf("unknown parent '%s'", name)}
+	}
+	result := &prefab{components: make(map[string]map[string]any)}
+	if p.extends != "" {
+		parent, err := resolvePrefab(p.extends, raw, resolved, append(chain, name))
+		if err != nil {
+			return nil, err
+		}
+		for component, fields := range parent.components {
+			result.components[component] = mergeFields(nil, fields)
+		}
+	}
+	for component, fields := range p.components {
+		result.components[component] = mergeFields(result.components[component], fields)
+	}
+	resolved[name] = result
+	return result, nil
+}
+
+func mergeFields(base, fields map[string]any) map[string]any {
+	result := make(map[string]any, len(base)+len(fields))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range fields {
+		result[k] = v
+	}
+	return result
+}
+
+func (ecs *ECS) validatePrefab(name string, p *prefab) error {
+	for component, fields := range p.components {
+		if _, err := ecs.buildComponent(name, component, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ### SPAWNING ###
+
+// Spawn creates an entity from a loaded prefab. Overrides replace the
+// prefab's component of the same type, or are added alongside it.
+func (ecs *ECS) Spawn(prefabName string, overrides ...Component) (Entity, error) {
+	ecs.prefabMutex.RLock()
+	p, found := ecs.prefabs[prefabName]
+	ecs.prefabMutex.RUnlock()
+	if !found {
+		return 0, &PrefabError{Prefab: prefabName, Reason: "unknown prefab"}
+	}
+	names := make([]string, 0, len(p.components))
+	for component := range p.components {
+		names = append(names, component)
+	}
+	slices.Sort(names)
+	components := make([]Component, 0, len(names)+len(overrides))
+	for _, component := range names {
+		c, err := ecs.buildComponent(prefabName, component, p.components[component])
+		if err != nil {
+			return 0, err
+		}
+		components = append(components, c)
+	}
+	for _, override := range overrides {
+		i := slices.IndexFunc(components, func(c Component) bool {
+			return c.Type() == override.Type()
+		})
+		if i < 0 {
+			components = append(components, override)
+		} else {
+			components[i] = override
+		}
+	}
+	entity := ecs.CreateEntity()
+	for _, c := range components {
+		ecs.AddComponent(entity, c)
+	}
+	return entity, nil
+}
+
+func (ecs *ECS) buildComponent(prefabName, name string, fields map[string]any) (Component, error) {
+	t, found := ecs.componentNames.lookup(name)
+	if !found {
+		return nil, &PrefabError{Prefab: prefabName, Field: name, Reason: "unknown component"}
+	}
+	var value reflect.Value
+	if t.Kind() == reflect.Pointer {
+		value = reflect.New(t.Elem())
+	} else {
+		value = reflect.New(t)
+	}
+	if err := assignField(value.Elem(), fields, name); err != nil {
+		err.Prefab = prefabName
+		return nil, err
+	}
+	if t.Kind() == reflect.Pointer {
+		return value.Interface().(Component), nil
+	}
+	return value.Elem().Interface().(Component), nil
+}
+
+// ### CONVERSION ###
+
+func fromLua(value lua.LValue) any {
+	switch v := value.(type) {
+	case lua.LBool:
+		return bool(v)
+	case lua.LNumber:
+		return float64(v)
+	case lua.LString:
+		return string(v)
+	case *lua.LTable:
+		if n := v.MaxN(); n > 0 {
+			list := make([]any, n)
+			for i := range n {
+				list[i] = fromLua(v.RawGetInt(i + 1))
+			}
+			return list
+		}
+		fields := make(map[string]any)
+		v.ForEach(func(key, value lua.LValue) {
+			fields[key.String()] = fromLua(value)
+		})
+		return fields
+	}
+	return nil
+}
+
+func assignField(dst reflect.Value, src any, path string) *PrefabError {
+	mismatch := func() *PrefabError {
+		return &PrefabError{Field: path, Reason: fmt.Sprintf("cannot use %T as %s", src, dst.Type())}
+	}
+	switch dst.Kind() {
+	case reflect.Bool:
+		v, ok := src.(bool)
+		if !ok {
+			return mismatch()
+		}
+		dst.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, ok := src.(float64)
+		if !ok || v != math.Trunc(v) || dst.OverflowInt(int64(v)) {
+			return mismatch()
+		}
+		dst.SetInt(int64(v))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, ok := src.(float64)
+		if !ok || v < 0 || v != math.Trunc(v) || dst.OverflowUint(uint64(v)) {
+			return mismatch()
+		}
+		dst.SetUint(uint64(v))
+	case reflect.Float32, reflect.Float64:
+		v, ok := src.(float64)
+		if !ok {
+			return mismatch()
+		}
+		dst.SetFloat(v)
+	case reflect.String:
+		v, ok := src.(string)
+		if !ok {
+			return mismatch()
+		}
+		dst.SetString(v)
+	case reflect.Slice, reflect.Array:
+		list, ok := src.([]any)
+		if fields, isTable := src.(map[string]any); isTable && len(fields) == 0 {
+			list, ok = []any{}, true
+		}
+		if !ok {
+			return mismatch()
+		}
+		if dst.Kind() == reflect.Slice {
+			dst.Set(reflect.MakeSlice(dst.Type(), len(list), len(list)))
+		} else if len(list) > dst.Len() {
+			return &PrefabError{Field: path, Reason: fmt.Sprintf("%d values for %s", len(list), dst.Type())}
+		}
+		for i, item := range list {
+			if err := assignField(dst.Index(i), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		fields, ok := src.(map[string]any)
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return mismatch()
+		}
+		dst.Set(reflect.MakeMapWithSize(dst.Type(), len(fields)))
+		for key, item := range fields {
+			value := reflect.New(dst.Type().Elem()).Elem()
+			if err := assignField(value, item, path+"."+key); err != nil {
+				return err
+			}
+			dst.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), value)
+		}
+	case reflect.Struct:
+		fields, ok := src.(map[string]any)
+		if !ok {
+			return mismatch()
+		}
+		for key, item := range fields {
+			field, found := dst.Type().FieldByName(key)
+			if !found || !field.IsExported() {
+				return &PrefabError{Field: path + "." + key, Reason: fmt.Sprintf("no field '%s' in %s", key, dst.Type())}
+			}
+			if err := assignField(dst.FieldByIndex(field.Index), item, path+"."+key); err != nil {
+				return err
+			}
+		}
+	case reflect.Pointer:
+		value := reflect.New(dst.Type().Elem())
+		if err := assignField(value.Elem(), src, path); err != nil {
+			return err
+		}
+		dst.Set(value)
+	default:
+		return mismatch()
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+package ecs
+
+import (
+	"iter"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// queryState caches the archetypes a query matches. Archetypes are never
+// removed, so the cache only has to look at archetypes created since the
+// query last ran, and iteration costs O(matches) once the world settles.
+type queryState struct {
+	ecs        *ECS
+	types      []reflect.Type
+	with       []reflect.Type
+	without    []reflect.Type
+	filters    []queryFilter
+	lastTick   atomic.Uint64
+	archetypes []*archetype
+	seen       int
+	cacheMutex sync.Mutex
+}
+
+type Query struct {
+	*queryState
+}
+
+type Query1[A Component] struct {
+	*queryState
+}
+
+type Query2[A, B Component] struct {
+	*queryState
+}
+
+type Query3[A, B, C Component] struct {
+	*queryState
+}
+
+type Row2[A, B Component] struct {
+	A A
+	B B
+}
+
+type Row3[A, B, C Component] struct {
+	A A
+	B B
+	C C
+}
+
+// ### CONSTRUCTORS ###
+
+func newQueryState(ecs *ECS, types ...reflect.Type) *queryState {
+	return &queryState{
+		ecs:        ecs,
+		types:      types,
+		with:       make([]reflect.Type, 0),
+		without:    make([]reflect.Type, 0),
+		filters:    make([]queryFilter, 0),
+		archetypes: make([]*archetype, 0),
+	}
+}
+
+func NewQuery(ecs *ECS, with []reflect.Type, without []reflect.Type) *Query {
+	q := newQueryState(ecs)
+	q.with = append(q.with, with...)
+	q.without = append(q.without, without...)
+	return &Query{q}
+}
+
+func NewQuery1[A Component](ecs *ECS) *Query1[A] {
+	return &Query1[A]{newQueryState(ecs, ComponentType[A]())}
+}
+
+func NewQuery2[A, B Component](ecs *ECS) *Query2[A, B] {
+	return &Query2[A, B]{newQueryState(ecs, ComponentType[A](), ComponentType[B]())}
+}
+
+func NewQuery3[A, B, C Component](ecs *ECS) *Query3[A, B, C] {
+	return &Query3[A, B, C]{newQueryState(ecs, ComponentType[A](), ComponentType[B](), ComponentType[C]())}
+}
+
+// ### FILTERS ###
+
+func (q *Query1[A]) With(types ...reflect.Type) *Query1[A] {
+	q.with = append(q.with, types...)
+	q.invalidate()
+	return q
+}
+
+func (q *Query1[A]) Without(types ...reflect.Type) *Query1[A] {
+	q.without = append(q.without, types...)
+	q.invalidate()
+	return q
+}
+
+func (q *Query2[A, B]) With(types ...reflect.Type) *Query2[A, B] {
+	q.with = append(q.with, types...)
+	q.invalidate()
+	return q
+}
+
+func (q *Query2[A, B]) Without(types ...reflect.Type) *Query2[A, B] {
+	q.without = append(q.without, types...)
+	q.invalidate()
+	return q
+}
+
+func (q *Query3[A, B, C]) With(types ...reflect.Type) *Query3[A, B, C] {
+	q.with = append(q.with, types...)
+	q.invalidate()
+	return q
+}
+
+func (q *Query3[A, B, C]) Without(types ...reflect.Type) *Query3[A, B, C] {
+	q.without = append(q.without, types...)
+	q.invalidate()
+	return q
+}
+
+// ### ITERATION ###
+
+func (q *Query) Entities() []Entity {
+	entities, _ := q.collect()
+	return entities
+}
+
+func (q *Query1[A]) Each(f func(entity Entity, a A)) {
+	for entity, a := range q.Iter() {
+		f(entity, a)
+	}
+}
+
+func (q *Query1[A]) Iter() iter.Seq2[Entity, A] {
+	return func(yield func(Entity, A) bool) {
+		entities, columns := q.collect()
+		for i, entity := range entities {
+			a, ok := ComponentCast[A](columns[0][i])
+			if !ok {
+				continue
+			}
+			if !yield(entity, a) {
+				return
+			}
+		}
+	}
+}
+
+func (q *Query1[A]) Get(entity Entity) (A, bool) {
+	components, ok := q.get(entity)
+	if !ok {
+		return *new(A), false
+	}
+	return ComponentCast[A](components[0])
+}
+
+func (q *Query2[A, B]) Each(f func(entity Entity, a A, b B)) {
+	for entity, row := range q.Iter() {
+		f(entity, row.A, row.B)
+	}
+}
+
+func (q *Query2[A, B]) Iter() iter.Seq2[Entity, Row2[A, B]] {
+	return func(yield func(Entity, Row2[A, B]) bool) {
+		entities, columns := q.collect()
+		for i, entity := range entities {
+			row, ok := castRow2[A, B](columns[0][i], columns[1][i])
+			if !ok {
+				continue
+			}
+			if !yield(entity, row) {
+				return
+			}
+		}
+	}
+}
+
+func (q *Query2[A, B]) Get(entity Entity) (Row2[A, B], bool) {
+	components, ok := q.get(entity)
+	if !ok {
+		return Row2[A, B]{}, false
+	}
+	return castRow2[A, B](components[0], components[1])
+}
+
+func (q *Query3[A, B, C]) Each(f func(entity Entity, a A, b B, c C)) {
+	for entity, row := range q.Iter() {
+		f(entity, row.A, row.B, row.C)
+	}
+}
+
+func (q *Query3[A, B, C]) Iter() iter.Seq2[Entity, Row3[A, B, C]] {
+	return func(yield func(Entity, Row3[A, B, C]) bool) {
+		entities, columns := q.collect()
+		for i, entity := range entities {
+			row, ok := castRow3[A, B, C](columns[0][i], columns[1][i], columns[2][i])
+			if !ok {
+				continue
+			}
+			if !yield(entity, row) {
+				return
+			}
+		}
+	}
+}
+
+func (q *Query3[A, B, C]) Get(entity Entity) (Row3[A, B, C], bool) {
+	components, ok := q.get(entity)
+	if !ok {
+		return Row3[A, B, C]{}, false
+	}
+	return castRow3[A, B, C](components[0], components[1], components[2])
+}
+
+// ### INTERNAL ###
+
+func castRow2[A, B Component](a, b Component) (Row2[A, B], bool) {
+	var row Row2[A, B]
+	var okA, okB bool
+	row.A, okA = ComponentCast[A](a)
+	row.B, okB = ComponentCast[B](b)
+	return row, okA && okB
+}
+
+func castRow3[A, B, C Component](a, b, c Component) (Row3[A, B, C], bool) {
+	var row Row3[A, B, C]
+	var okA, okB, okC bool
+	row.A, okA = ComponentCast[A](a)
+	row.B, okB = ComponentCast[B](b)
+	row.C, okC = ComponentCast[C](c)
+	return row, okA && okB && okC
+}
+
+func (q *queryState) matches(a *archetype) bool {
+	return a.matches(q.types, nil) && a.matches(q.with, q.without)
+}
+
+// collect copies the matching rows out under the read lock, so systems are
+// free to make structural changes while they iterate.
+func (q *queryState) collect() ([]Entity, [][]Component) {
+	entities := make([]Entity, 0)
+	columns := make([][]Component, len(q.types))
+	q.ecs.mutex.RLock()
+	tick := q.ecs.changeTick.Load()
+	since := q.lastTick.Load()
+	for _, a := range q.matchingArchetypes() {
+		if len(q.filters) == 0 {
+			entities = append(entities, a.entities...)
+			for i, t := range q.types {
+				columns[i] = append(columns[i], a.data[a.columns[t]]...)
+			}
+			continue
+		}
+		for row, entity := range a.entities {
+			if !matchesFilters(q.filters, a, row, since) {
+				continue
+			}
+			entities = append(entities, entity)
+			for i, t := range q.types {
+				columns[i] = append(columns[i], a.data[a.columns[t]][row])
+			}
+		}
+	}
+	q.ecs.mutex.RUnlock()
+	q.lastTick.Store(tick)
+	return entities, columns
+}
+
+func (q *queryState) invalidate() {
+	q.cacheMutex.Lock()
+	q.archetypes = q.archetypes[:0]
+	q.seen = 0
+	q.cacheMutex.Unlock()
+}
+
+// matchingArchetypes brings the cache up to date with archetypes created
+// since the last call. The caller must hold the world's read lock.
+func (q *queryState) matchingArchetypes() []*archetype {
+	q.cacheMutex.Lock()
+	defer q.cacheMutex.Unlock()
+	for _, a := range q.ecs.archetypes[q.seen:] {
+		if q.matches(a) {
+			q.archetypes = append(q.archetypes, a)
+		}
+	}
+	q.seen = len(q.ecs.archetypes)
+	return q.archetypes
+}
+
+func (q *queryState) get(entity Entity) ([]Component, bool) {
+	q.ecs.mutex.RLock()
+	defer q.ecs.mutex.RUnlock()
+	location, found := q.ecs.location(entity)
+	if !found || !q.matches(location.archetype) || !matchesFilters(q.filters, location.archetype, location.row, q.lastTick.Load()) {
+		return nil, false
+	}
+	a := location.archetype
+	result := make([]Component, len(q.types))
+	for i, t := range q.types {
+		result[i] = a.data[a.columns[t]][location.row]
+	}
+	return result, true
+}
@@ -0,0 +1,179 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Pair relates its entity to one or more target entities through the
+// relation kind R, such as Pair[Owns] or Pair[Targets]. Targets should be
+// changed through AddPair and RemovePair so the reverse index stays in step.
+type Pair[R any] struct {
+	Targets []Entity
+}
+
+type pair interface {
+	Component
+	targets() []Entity
+	removeTarget(target Entity) bool
+}
+
+func (*Pair[R]) Type() reflect.Type {
+	return reflect.TypeOf(Pair[R]{})
+}
+
+func (p *Pair[R]) targets() []Entity {
+	return p.Targets
+}
+
+func (p *Pair[R]) removeTarget(target Entity) bool {
+	i := slices.Index(p.Targets, target)
+	if i < 0 {
+		return false
+	}
+	p.Targets = slices.Delete(p.Targets, i, i+1)
+	return true
+}
+
+// ### RELATION FUNCTIONS ###
+
+func AddPair[R any](ecs *ECS, source, target Entity) bool {
+	if !ecs.IsAlive(target) {
+		return false
+	}
+	t := ComponentType[*Pair[R]]()
+	existing, found := NewQuery1[*Pair[R]](ecs).Get(source)
+	if !found {
+		ecs.AddComponent(source, &Pair[R]{Targets: []Entity{target}})
+		return ecs.HasComponent(source, t)
+	}
+	ecs.mutex.Lock()
+	if slices.Contains(existing.Targets, target) {
+		ecs.mutex.Unlock()
+		return true
+	}
+	existing.Targets = append(existing.Targets, target)
+	ecs.indexPairTarget(t, source, target)
+	ecs.mutex.Unlock()
+	ecs.MarkChanged(source, t)
+	return true
+}
+
+func RemovePair[R any](ecs *ECS, source, target Entity) bool {
+	return ecs.removePairTarget(ComponentType[*Pair[R]](), source, target)
+}
+
+func HasPair[R any](ecs *ECS, source, target Entity) bool {
+	existing, found := NewQuery1[*Pair[R]](ecs).Get(source)
+	if !found {
+		return false
+	}
+	ecs.mutex.RLock()
+	defer ecs.mutex.RUnlock()
+	return slices.Contains(existing.Targets, target)
+}
+
+func Targets[R any](ecs *ECS, source Entity) []Entity {
+	existing, found := NewQuery1[*Pair[R]](ecs).Get(source)
+	if !found {
+		return nil
+	}
+	ecs.mutex.RLock()
+	defer ecs.mutex.RUnlock()
+	return slices.Clone(existing.Targets)
+}
+
+func Target[R any](ecs *ECS, source Entity) (Entity, bool) {
+	targets := Targets[R](ecs, source)
+	if len(targets) == 0 {
+		return 0, false
+	}
+	return targets[0], true
+}
+
+// Sources returns every entity with an R relation to target.
+func Sources[R any](ecs *ECS, target Entity) []Entity {
+	ecs.mutex.RLock()
+	defer ecs.mutex.RUnlock()
+	return slices.Clone(ecs.pairSources[ComponentType[*Pair[R]]()][target])
+}
+
+// ### REVERSE INDEX ###
+
+// The index functions below expect the world lock to be held.
+
+func (ecs *ECS) indexPair(source Entity, component Component) {
+	if p, ok := component.(pair); ok {
+		for _, target := range p.targets() {
+			ecs.indexPairTarget(p.Type(), source, target)
+		}
+	}
+}
+
+func (ecs *ECS) unindexPair(source Entity, component Component) {
+	if p, ok := component.(pair); ok {
+		for _, target := range p.targets() {
+			ecs.unindexPairTarget(p.Type(), source, target)
+		}
+	}
+}
+
+func (ecs *ECS) indexPairTarget(t reflect.Type, source, target Entity) {
+	targets, found := ecs.pairSources[t]
+	if !found {
+		targets = make(map[Entity][]Entity)
+		ecs.pairSources[t] = targets
+	}
+	if !slices.Contains(targets[target], source) {
+		targets[target] = append(targets[target], source)
+	}
+}
+
+func (ecs *ECS) unindexPairTarget(t reflect.Type, source, target Entity) {
+	targets := ecs.pairSources[t]
+	sources := targets[target]
+	if i := slices.Index(sources, source); i >= 0 {
+		sources = slices.Delete(sources, i, i+1)
+	}
+	if len(sources) == 0 {
+		delete(targets, target)
+	} else {
+		targets[target] = sources
+	}
+}
+
+// incomingPairs removes target from the reverse index and returns, per
+// relation type, the entities that still point at it.
+func (ecs *ECS) incomingPairs(target Entity) map[reflect.Type][]Entity {
+	result := make(map[reflect.Type][]Entity)
+	for t, targets := range ecs.pairSources {
+		if sources, found := targets[target]; found {
+			result[t] = sources
+			delete(targets, target)
+		}
+	}
+	return result
+}
+
+func (ecs *ECS) removePairTarget(t reflect.Type, source, target Entity) bool {
+	ecs.mutex.Lock()
+	location, found := ecs.location(source)
+	if !found || !location.archetype.has(t) {
+		ecs.mutex.Unlock()
+		return false
+	}
+	p, ok := location.archetype.get(location.row, t).(pair)
+	if !ok || !p.removeTarget(target) {
+		ecs.mutex.Unlock()
+		return false
+	}
+	ecs.unindexPairTarget(t, source, target)
+	empty := len(p.targets()) == 0
+	ecs.mutex.Unlock()
+	if empty {
+		ecs.RemoveComponent(source, t)
+	} else {
+		ecs.MarkChanged(source, t)
+	}
+	return true
+}
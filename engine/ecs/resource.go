@@ -0,0 +1,94 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+type ResourceError struct {
+	Type   reflect.Type
+	Reason string
+}
+
+func (e *ResourceError) Error() string {
+	return fmt.Sprintf("Error accessing resource '%s': %s", e.Type, e.Reason)
+}
+
+// ### TYPED RESOURCES ###
+
+// InsertResource stores value as the resource of type T, replacing any
+// resource already stored under that type.
+func InsertResource[T any](ecs *ECS, value T) {
+	ecs.resourceMutex.Lock()
+	ecs.resources[reflect.TypeFor[T]()] = &value
+	delete(ecs.shared, reflect.TypeFor[T]())
+	ecs.resourceMutex.Unlock()
+}
+
+// ResourceOf returns the resource of type T for reading. Systems pass the
+// commands they were handed, and T must then be declared in the system's
+// Reads or Writes. Outside systems, commands is nil.
+func ResourceOf[T any](ecs *ECS, commands *Commands) (*T, error) {
+	return resource[T](ecs, commands, false)
+}
+
+// ResourceMut returns the resource of type T for writing. Systems pass the
+// commands they were handed, and T must then be declared in their Writes.
+func ResourceMut[T any](ecs *ECS, commands *Commands) (*T, error) {
+	return resource[T](ecs, commands, true)
+}
+
+func RemoveResource[T any](ecs *ECS) bool {
+	t := reflect.TypeFor[T]()
+	ecs.resourceMutex.Lock()
+	defer ecs.resourceMutex.Unlock()
+	_, found := ecs.resources[t]
+	delete(ecs.resources, t)
+	delete(ecs.shared, t)
+	return found
+}
+
+func resource[T any](ecs *ECS, commands *Commands, write bool) (*T, error) {
+	t := reflect.TypeFor[T]()
+	if err := ecs.checkAccess(commands, t, write); err != nil {
+		return nil, err
+	}
+	ecs.resourceMutex.RLock()
+	value, found := ecs.resources[t]
+	shared := ecs.shared[t]
+	ecs.resourceMutex.RUnlock()
+	if !found {
+		return nil, &ResourceError{Type: t, Reason: "not found"}
+	}
+	if write && shared {
+		return nil, &ResourceError{Type: t, Reason: "shared from another world, so it can only be read"}
+	}
+	result, ok := value.(*T)
+	if !ok {
+		return nil, &ResourceError{Type: t, Reason: fmt.Sprintf("stored as %T, not a pointer", value)}
+	}
+	return result, nil
+}
+
+// ### ACCESS TRACKING ###
+
+func (ecs *ECS) checkAccess(commands *Commands, t reflect.Type, write bool) error {
+	if commands == nil || commands.system == nil {
+		if ecs.running.Load() {
+			return &ResourceError{Type: t, Reason: "accessed while systems run without the calling system's commands"}
+		}
+		return nil
+	}
+	system := commands.system
+	if system.isExclusive() {
+		return nil
+	}
+	if write && !slices.Contains(system.writes, t) {
+		return &ResourceError{Type: t, Reason: "written by a system that doesn't declare it in Writes"}
+	}
+	if !write && !slices.Contains(system.reads, t) && !slices.Contains(system.writes, t) {
+		return &ResourceError{Type: t, Reason: "read by a system that doesn't declare it in Reads or Writes"}
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SystemConfig struct {
+	ecs        *ECS
+	system     FallibleSystem
+	name       string
+	label      string
+	before     []string
+	after      []string
+	reads      []reflect.Type
+	writes     []reflect.Type
+	exclusive  bool
+	conditions []Condition
+	policy     FailurePolicy
+	disabled   bool
+	lastRun    uint64
+	thisRun    uint64
+	timings    timings
+}
+
+type batch []*SystemConfig
+
+type stageSchedule struct {
+	name         string
+	systems      []*SystemConfig
+	order        []*SystemConfig
+	predecessors map[*SystemConfig][]*SystemConfig
+	plan         []batch
+}
+
+func newSystemConfig(ecs *ECS, system FallibleSystem, name string) *SystemConfig {
+	return &SystemConfig{
+		ecs:    ecs,
+		system: system,
+		name:   name,
+		before: make([]string, 0),
+		after:  make([]string, 0),
+		reads:  make([]reflect.Type, 0),
+		writes: make([]reflect.Type, 0),
+	}
+}
+
+// infallible adapts a System to the FallibleSystem the scheduler runs.
+func infallible(system System) FallibleSystem {
+	return func(ecs *ECS, commands *Commands) error {
+		system(ecs, commands)
+		return nil
+	}
+}
+
+func systemName(system any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(system).Pointer()).Name()
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// ### ACCESS DECLARATIONS ###
+
+// Reads declares component or resource types the system only reads.
+// A system that declares no access at all is treated as exclusive.
+func (c *SystemConfig) Reads(types ...reflect.Type) *SystemConfig {
+	c.reads = append(c.reads, types...)
+	c.ecs.invalidateSchedule()
+	return c
+}
+
+func (c *SystemConfig) Writes(types ...reflect.Type) *SystemConfig {
+	c.writes = append(c.writes, types...)
+	c.ecs.invalidateSchedule()
+	return c
+}
+
+func (c *SystemConfig) Exclusive() *SystemConfig {
+	c.exclusive = true
+	c.ecs.invalidateSchedule()
+	return c
+}
+
+func (c *SystemConfig) isExclusive() bool {
+	return c.exclusive || len(c.reads) == 0 && len(c.writes) == 0
+}
+
+func (c *SystemConfig) conflicts(other *SystemConfig) bool {
+	if c.isExclusive() || other.isExclusive() {
+		return true
+	}
+	for _, w := range c.writes {
+		if slices.Contains(other.reads, w) || slices.Contains(other.writes, w) {
+			return true
+		}
+	}
+	for _, w := range other.writes {
+		if slices.Contains(c.reads, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// ### STAGES ###
+
+func newStageSchedule(name string) *stageSchedule {
+	return &stageSchedule{name: name, systems: make([]*SystemConfig, 0)}
+}
+
+func (ecs *ECS) AddStageBefore(stage uint) (uint, bool) {
+	return ecs.insertStage(stage, 0)
+}
+
+func (ecs *ECS) AddStageAfter(stage uint) (uint, bool) {
+	return ecs.insertStage(stage, 1)
+}
+
+func (ecs *ECS) insertStage(stage uint, offset int) (uint, bool) {
+	ecs.scheduleMutex.Lock()
+	defer ecs.scheduleMutex.Unlock()
+	for _, sequence := range []*[]uint{&ecs.startupStages, &ecs.frameStages, &ecs.shutdownStages} {
+		i := slices.Index(*sequence, stage)
+		if i < 0 {
+			continue
+		}
+		id := uint(len(ecs.stages))
+		ecs.stages = append(ecs.stages, newStageSchedule(fmt.Sprintf("Stage%d", id)))
+		*sequence = slices.Insert(*sequence, i+offset, id)
+		return id, true
+	}
+	return 0, false
+}
+
+// ### PLANNING ###
+
+func (ecs *ECS) invalidateSchedule() {
+	ecs.scheduleMutex.Lock()
+	for _, s := range ecs.stages {
+		s.plan = nil
+	}
+	ecs.scheduleMutex.Unlock()
+}
+
+// plan splits a stage into batches of mutually compatible systems. A system
+// joins the current batch only if it neither conflicts with nor is ordered
+// after anything already in it.
+func (ecs *ECS) plan(stage uint) ([]batch, error) {
+	ecs.scheduleMutex.Lock()
+	defer ecs.scheduleMutex.Unlock()
+	schedule := ecs.stages[stage]
+	if schedule.plan != nil {
+		return schedule.plan, nil
+	}
+	if err := schedule.resolve(); err != nil {
+		return nil, err
+	}
+	plan := make([]batch, 0)
+	var current batch
+	for _, system := range schedule.order {
+		for _, other := range current {
+			if system.conflicts(other) || slices.Contains(schedule.predecessors[system], other) {
+				plan = append(plan, current)
+				current = nil
+				break
+			}
+		}
+		current = append(current, system)
+	}
+	if current != nil {
+		plan = append(plan, current)
+	}
+	schedule.plan = plan
+	return plan, nil
+}
+
+func (ecs *ECS) Plan(stage uint) ([][]string, error) {
+	plan, err := ecs.plan(stage)
+	if err != nil {
+		return nil, err
+	}
+	result := make([][]string, len(plan))
+	for i, b := range plan {
+		result[i] = make([]string, len(b))
+		for j, system := range b {
+			result[i][j] = system.displayName()
+		}
+	}
+	return result, nil
+}
+
+// ### EXECUTION ###
+
+// runStage runs each batch of the stage on a worker pool, then applies every
+// system's commands in schedule order once they have all finished.
+func (ecs *ECS) runStage(stage uint, threads int) error {
+	plan, err := ecs.plan(stage)
+	if err != nil {
+		return err
+	}
+	name := ecs.stages[stage].name
+	commands := make([]*Commands, 0)
+	for _, b := range plan {
+		if b, err = ecs.runnable(name, b); err != nil {
+			break
+		}
+		if len(b) == 0 {
+			continue
+		}
+		batchCommands, errs := ecs.runBatch(b, threads)
+		err = ecs.handleFailures(name, b, batchCommands, errs)
+		commands = append(commands, batchCommands...)
+		if err != nil {
+			break
+		}
+	}
+	// a stopping failure still leaves the work of the systems that succeeded
+	for _, c := range commands {
+		ecs.ApplyCommands(c)
+	}
+	return err
+}
+
+// runSystem runs one system, turning a panic into a PanicError.
+func (ecs *ECS) runSystem(system *SystemConfig, commands *Commands) (err error) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+		system.timings.record(time.Since(start))
+	}()
+	system.lastRun, system.thisRun = system.thisRun, ecs.changeTick.Load()
+	return system.system(ecs, commands)
+}
+
+// runBatch runs the batch with up to threads workers. A single thread runs
+// the batch on the calling goroutine, which the render stage relies on.
+func (ecs *ECS) runBatch(b batch, threads int) ([]*Commands, []error) {
+	if threads == 0 || threads > len(b) {
+		threads = len(b)
+	}
+	commands := make([]*Commands, len(b))
+	errs := make([]error, len(b))
+	ecs.running.Store(true)
+	defer ecs.running.Store(false)
+	if threads == 1 {
+		for i := range b {
+			commands[i] = &Commands{ecs: ecs, commands: make([]command, 0), system: b[i]}
+			errs[i] = ecs.runSystem(b[i], commands[i])
+		}
+		return commands, errs
+	}
+	indices := make(chan int, len(b))
+	var wg sync.WaitGroup
+	for range threads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				errs[i] = ecs.runSystem(b[i], commands[i])
+			}
+		}()
+	}
+	for i := range b {
+		commands[i] = &Commands{ecs: ecs, commands: make([]command, 0), system: b[i]}
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return commands, errs
+}
@@ -0,0 +1,310 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type Format uint8
+
+const (
+	FormatJSON Format = iota
+	FormatBinary
+)
+
+type registry struct {
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+	mutex sync.RWMutex
+}
+
+type worldDocument struct {
+	Entities  []entityDocument `json:"entities"`
+	Resources []valueDocument  `json:"resources"`
+}
+
+type entityDocument struct {
+	ID         Entity          `json:"id"`
+	Components []valueDocument `json:"components"`
+}
+
+type valueDocument struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+var entityType = reflect.TypeOf(Entity(0))
+
+func newRegistry() *registry {
+	return &registry{
+		names: make(map[reflect.Type]string),
+		types: make(map[string]reflect.Type),
+	}
+}
+
+// ### REGISTRATION ###
+
+// RegisterComponentName gives a component type a stable name for saving and
+// loading. Components whose type has no name are left out of saved worlds.
+func (ecs *ECS) RegisterComponentName(name string, component Component) {
+	ecs.componentNames.register(name, component.Type(), reflect.TypeOf(component))
+}
+
+// RegisterResourceName opts a resource type into saving and loading.
+func (ecs *ECS) RegisterResourceName(name string, resource Resource) {
+	ecs.resourceNames.register(name, resource.Type(), reflect.TypeOf(resource))
+}
+
+func (r *registry) register(name string, key reflect.Type, concrete reflect.Type) {
+	r.mutex.Lock()
+	r.names[key] = name
+	r.types[name] = concrete
+	r.mutex.Unlock()
+}
+
+func (r *registry) name(key reflect.Type) (string, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	name, found := r.names[key]
+	return name, found
+}
+
+func (r *registry) lookup(name string) (reflect.Type, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	t, found := r.types[name]
+	return t, found
+}
+
+// ### SAVING ###
+
+func (ecs *ECS) Save(w io.Writer, format Format) error {
+	document, err := ecs.document(format)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "\t")
+		return encoder.Encode(document)
+	case FormatBinary:
+		return gob.NewEncoder(w).Encode(document)
+	}
+	return fmt.Errorf("unknown format %d", format)
+}
+
+func (ecs *ECS) document(format Format) (worldDocument, error) {
+	document := worldDocument{
+		Entities:  make([]entityDocument, 0),
+		Resources: make([]valueDocument, 0),
+	}
+	ecs.mutex.RLock()
+	defer ecs.mutex.RUnlock()
+	for _, a := range ecs.archetypes {
+		for row, entity := range a.entities {
+			entry := entityDocument{ID: entity, Components: make([]valueDocument, 0, len(a.types))}
+			for i, t := range a.types {
+				name, found := ecs.componentNames.name(t)
+				if !found {
+					continue
+				}
+				data, err := marshal(format, a.data[i][row])
+				if err != nil {
+					return document, fmt.Errorf("saving component '%s': %w", name, err)
+				}
+				entry.Components = append(entry.Components, valueDocument{Type: name, Data: data})
+			}
+			document.Entities = append(document.Entities, entry)
+		}
+	}
+	slices.SortFunc(document.Entities, func(a, b entityDocument) int {
+		return int(a.ID.Index()) - int(b.ID.Index())
+	})
+	ecs.resourceMutex.RLock()
+	defer ecs.resourceMutex.RUnlock()
+	for t, resource := range ecs.resources {
+		name, found := ecs.resourceNames.name(t)
+		if !found {
+			continue
+		}
+		data, err := marshal(format, resource)
+		if err != nil {
+			return document, fmt.Errorf("saving resource '%s': %w", name, err)
+		}
+		document.Resources = append(document.Resources, valueDocument{Type: name, Data: data})
+	}
+	slices.SortFunc(document.Resources, func(a, b valueDocument) int {
+		return strings.Compare(a.Type, b.Type)
+	})
+	return document, nil
+}
+
+// ### LOADING ###
+
+// Load spawns every entity in the saved world into this one. Entity
+// references inside loaded components are remapped to the new entities.
+func (ecs *ECS) Load(r io.Reader, format Format) error {
+	var document worldDocument
+	var err error
+	switch format {
+	case FormatJSON:
+		err = json.NewDecoder(r).Decode(&document)
+	case FormatBinary:
+		err = gob.NewDecoder(r).Decode(&document)
+	default:
+		err = fmt.Errorf("unknown format %d", format)
+	}
+	if err != nil {
+		return err
+	}
+	mapping := make(map[Entity]Entity, len(document.Entities))
+	for _, entry := range document.Entities {
+		mapping[entry.ID] = ecs.CreateEntity()
+	}
+	for _, entry := range document.Entities {
+		for _, c := range entry.Components {
+			value, err := ecs.decode(ecs.componentNames, format, c, mapping)
+			if err != nil {
+				return err
+			}
+			component, ok := value.(Component)
+			if !ok {
+				return fmt.Errorf("type '%s' is not a component", c.Type)
+			}
+			ecs.AddComponent(mapping[entry.ID], component)
+		}
+	}
+	for _, r := range document.Resources {
+		value, err := ecs.decode(ecs.resourceNames, format, r, mapping)
+		if err != nil {
+			return err
+		}
+		resource, ok := value.(Resource)
+		if !ok {
+			return fmt.Errorf("type '%s' is not a resource", r.Type)
+		}
+		ecs.RegisterResource(resource)
+	}
+	return nil
+}
+
+func (ecs *ECS) decode(r *registry, format Format, document valueDocument, mapping map[Entity]Entity) (any, error) {
+	t, found := r.lookup(document.Type)
+	if !found {
+		return nil, fmt.Errorf("unknown type '%s'", document.Type)
+	}
+	var value reflect.Value
+	if t.Kind() == reflect.Pointer {
+		value = reflect.New(t.Elem())
+	} else {
+		value = reflect.New(t)
+	}
+	if err := unmarshal(format, document.Data, value.Interface()); err != nil {
+		return nil, fmt.Errorf("loading '%s': %w", document.Type, err)
+	}
+	remapEntities(value, mapping)
+	if t.Kind() == reflect.Pointer {
+		return value.Interface(), nil
+	}
+	return value.Elem().Interface(), nil
+}
+
+// ### ENCODING ###
+
+func marshal(format Format, value any) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.Marshal(value)
+	case FormatBinary:
+		if !hasExportedFields(reflect.TypeOf(value)) {
+			return nil, nil
+		}
+		var buffer bytes.Buffer
+		err := gob.NewEncoder(&buffer).Encode(value)
+		return buffer.Bytes(), err
+	}
+	return nil, fmt.Errorf("unknown format %d", format)
+}
+
+func unmarshal(format Format, data []byte, value any) error {
+	switch format {
+	case FormatJSON:
+		return json.Unmarshal(data, value)
+	case FormatBinary:
+		if len(data) == 0 {
+			return nil
+		}
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+	}
+	return fmt.Errorf("unknown format %d", format)
+}
+
+// hasExportedFields reports whether gob can encode the type, since gob
+// refuses structs without exported fields, such as tag components.
+func hasExportedFields(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// ### ENTITY REMAPPING ###
+
+// remapEntities rewrites every reachable Entity value through mapping.
+// Entities missing from the mapping become the zero entity.
+func remapEntities(v reflect.Value, mapping map[Entity]Entity) {
+	if v.Type() == entityType {
+		if v.CanSet() {
+			v.SetUint(uint64(mapping[Entity(v.Uint())]))
+		}
+		return
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			remapEntities(v.Elem(), mapping)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				remapEntities(v.Field(i), mapping)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			remapEntities(v.Index(i), mapping)
+		}
+	case reflect.Map:
+		if v.IsNil() || !v.CanSet() {
+			return
+		}
+		remapped := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := reflect.New(v.Type().Key()).Elem()
+			key.Set(iter.Key())
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			remapEntities(key, mapping)
+			remapEntities(value, mapping)
+			remapped.SetMapIndex(key, value)
+		}
+		v.Set(remapped)
+	}
+}
@@ -0,0 +1,222 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Cloner lets a component control how it's copied into a snapshot. Without
+// it, components are copied by value with slices and maps duplicated, while
+// pointers inside the component are shared.
+type Cloner interface {
+	Clone() Component
+}
+
+// NoSnapshot opts a component out of snapshots. The snapshot keeps a
+// reference to the live component rather than a copy, so restoring puts the
+// component back without rolling back its state.
+type NoSnapshot interface {
+	NoSnapshot()
+}
+
+// Snapshot is a copy of a world's component data and entity allocator. It
+// doesn't cover resources, events or the schedule.
+type Snapshot struct {
+	world        *ECS
+	nextEntity   uint64
+	entities     []entityRecord
+	freeEntities []uint32
+	archetypes   []archetypeSnapshot
+	pairSources  map[reflect.Type]map[Entity][]Entity
+}
+
+type archetypeSnapshot struct {
+	entities []Entity
+	data     [][]Component
+	added    [][]uint64
+}
+
+var deepTypes sync.Map
+
+// ### SNAPSHOT AND RESTORE ###
+
+func (ecs *ECS) Snapshot() *Snapshot {
+	ecs.mutex.RLock()
+	defer ecs.mutex.RUnlock()
+	snapshot := &Snapshot{
+		world:        ecs,
+		nextEntity:   ecs.nextEntity.Load(),
+		entities:     slices.Clone(ecs.entities),
+		freeEntities: slices.Clone(ecs.freeEntities),
+		archetypes:   make([]archetypeSnapshot, len(ecs.archetypes)),
+		pairSources:  make(map[reflect.Type]map[Entity][]Entity, len(ecs.pairSources)),
+	}
+	for i, a := range ecs.archetypes {
+		copied := archetypeSnapshot{
+			entities: slices.Clone(a.entities),
+			data:     make([][]Component, len(a.data)),
+			added:    make([][]uint64, len(a.added)),
+		}
+		for column := range a.data {
+			copied.data[column] = make([]Component, len(a.data[column]))
+			for row, component := range a.data[column] {
+				copied.data[column][row] = cloneComponent(component)
+			}
+			copied.added[column] = slices.Clone(a.added[column])
+		}
+		snapshot.archetypes[i] = copied
+	}
+	for t, targets := range ecs.pairSources {
+		copied := make(map[Entity][]Entity, len(targets))
+		for target, sources := range targets {
+			copied[target] = slices.Clone(sources)
+		}
+		snapshot.pairSources[t] = copied
+	}
+	return snapshot
+}
+
+// Restore puts the world back to the state captured by snapshot. Component
+// hooks don't run, only those registered with OnRestore, and every restored
+// component counts as changed for Changed filters.
+// A snapshot can be restored any number of times, but only into the world
+// it was taken from.
+func (ecs *ECS) Restore(snapshot *Snapshot) bool {
+	if snapshot.world != ecs {
+		return false
+	}
+	ecs.mutex.Lock()
+	tick := ecs.changeTick.Add(1)
+	ecs.nextEntity.Store(snapshot.nextEntity)
+	ecs.entities = slices.Clone(snapshot.entities)
+	ecs.freeEntities = slices.Clone(snapshot.freeEntities)
+	for i, a := range ecs.archetypes {
+		if i >= len(snapshot.archetypes) {
+			// created after the snapshot, so it was empty at the time
+			a.entities = a.entities[:0]
+			for column := range a.data {
+				clear(a.data[column])
+				a.data[column] = a.data[column][:0]
+				a.added[column] = a.added[column][:0]
+				a.changed[column] = a.changed[column][:0]
+			}
+			continue
+		}
+		saved := snapshot.archetypes[i]
+		a.entities = slices.Clone(saved.entities)
+		for column := range a.data {
+			a.data[column] = make([]Component, len(saved.data[column]))
+			for row, component := range saved.data[column] {
+				a.data[column][row] = cloneComponent(component)
+			}
+			a.added[column] = slices.Clone(saved.added[column])
+			a.changed[column] = slices.Repeat([]uint64{tick}, len(saved.entities))
+		}
+	}
+	ecs.pairSources = make(map[reflect.Type]map[Entity][]Entity, len(snapshot.pairSources))
+	for t, targets := range snapshot.pairSources {
+		copied := make(map[Entity][]Entity, len(targets))
+		for target, sources := range targets {
+			copied[target] = slices.Clone(sources)
+		}
+		ecs.pairSources[t] = copied
+	}
+	ecs.mutex.Unlock()
+	ecs.hookMutex.RLock()
+	hooks := ecs.restoreHooks
+	ecs.hookMutex.RUnlock()
+	for _, hook := range hooks {
+		hook(ecs)
+	}
+	return true
+}
+
+// ### COPYING ###
+
+func cloneComponent(component Component) Component {
+	switch c := component.(type) {
+	case NoSnapshot:
+		return component
+	case Cloner:
+		return c.Clone()
+	}
+	v := reflect.ValueOf(component)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return component
+		}
+		copied := reflect.New(v.Type().Elem())
+		copyValue(copied.Elem(), v.Elem())
+		return copied.Interface().(Component)
+	}
+	copied := reflect.New(v.Type()).Elem()
+	copyValue(copied, v)
+	return copied.Interface().(Component)
+}
+
+// copyValue copies src into dst, duplicating slices and maps reachable
+// through exported fields so the copy doesn't share their storage.
+func copyValue(dst, src reflect.Value) {
+	dst.Set(src)
+	if !isDeep(src.Type()) {
+		return
+	}
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := range src.NumField() {
+			if src.Type().Field(i).IsExported() {
+				copyValue(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Array:
+		for i := range src.Len() {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		copied := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := range src.Len() {
+			copyValue(copied.Index(i), src.Index(i))
+		}
+		dst.Set(copied)
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		copied := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			value := reflect.New(src.Type().Elem()).Elem()
+			copyValue(value, iter.Value())
+			copied.SetMapIndex(iter.Key(), value)
+		}
+		dst.Set(copied)
+	}
+}
+
+// isDeep reports whether values of t hold slices or maps that a plain
+// assignment would share.
+func isDeep(t reflect.Type) bool {
+	if deep, found := deepTypes.Load(t); found {
+		return deep.(bool)
+	}
+	deep := false
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map:
+		deep = true
+	case reflect.Array:
+		deep = isDeep(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if t.Field(i).IsExported() && isDeep(t.Field(i).Type) {
+				deep = true
+				break
+			}
+		}
+	}
+	deepTypes.Store(t, deep)
+	return deep
+}
@@ -0,0 +1,190 @@
+package ecs
+
+import (
+	"reflect"
+	"sync"
+)
+
+// AppState is a resource holding the current value of a state machine over
+// S, such as an enum of Menu, Playing and Paused. Transitions requested with
+// Set are queued and applied between frames, running the OnExit systems of
+// the old state and then the OnEnter systems of the new one.
+type AppState[S comparable] struct {
+	current S
+	next    S
+	queued  bool
+	mutex   sync.RWMutex
+}
+
+// StateScoped marks an entity as belonging to a state. It's destroyed,
+// along with its descendants, when that state is exited.
+type StateScoped[S comparable] struct {
+	State S
+}
+
+type stateMachine interface {
+	transition(ecs *ECS) error
+}
+
+type stateSystems[S comparable] struct {
+	state   *AppState[S]
+	enter   map[S][]*SystemConfig
+	exit    map[S][]*SystemConfig
+	entered bool
+}
+
+func (*AppState[S]) Type() reflect.Type {
+	return reflect.TypeOf(AppState[S]{})
+}
+
+func (*StateScoped[S]) Type() reflect.Type {
+	return reflect.TypeOf(StateScoped[S]{})
+}
+
+func (s *AppState[S]) Get() S {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.current
+}
+
+// Set queues a transition to next. Setting the state more than once in a
+// frame keeps only the last request.
+func (s *AppState[S]) Set(next S) {
+	s.mutex.Lock()
+	s.next = next
+	s.queued = true
+	s.mutex.Unlock()
+}
+
+// ### REGISTRATION ###
+
+// AddState registers the AppState[S] resource starting in initial. The
+// OnEnter systems of initial run before the first frame.
+func AddState[S comparable](ecs *ECS, initial S) *AppState[S] {
+	state := &AppState[S]{current: initial}
+	ecs.resourceMutex.Lock()
+	ecs.resources[reflect.TypeFor[AppState[S]]()] = state
+	ecs.resourceMutex.Unlock()
+	ecs.scheduleMutex.Lock()
+	stateSystemsOf[S](ecs).state = state
+	ecs.scheduleMutex.Unlock()
+	return state
+}
+
+func OnEnter[S comparable](ecs *ECS, state S, system System) *SystemConfig {
+	return registerTransition(ecs, state, system, true)
+}
+
+func OnExit[S comparable](ecs *ECS, state S, system System) *SystemConfig {
+	return registerTransition(ecs, state, system, false)
+}
+
+// OnUpdate registers system in stage to run only while the AppState[S] is
+// state.
+func OnUpdate[S comparable](ecs *ECS, state S, system System, stage uint) *SystemConfig {
+	return ecs.RegisterSystem(system, stage).RunIf(InState(state))
+}
+
+func InState[S comparable](state S) Condition {
+	return ResourceMatches(func(s *AppState[S]) bool {
+		return s.Get() == state
+	})
+}
+
+func registerTransition[S comparable](ecs *ECS, state S, system System, enter bool) *SystemConfig {
+	config := newSystemConfig(ecs, infallible(system), systemName(system))
+	ecs.scheduleMutex.Lock()
+	defer ecs.scheduleMutex.Unlock()
+	systems := stateSystemsOf[S](ecs)
+	if enter {
+		systems.enter[state] = append(systems.enter[state], config)
+	} else {
+		systems.exit[state] = append(systems.exit[state], config)
+	}
+	return config
+}
+
+// stateSystemsOf expects the schedule lock to be held. Transition systems
+// may be registered before AddState, so the entry is created on first use.
+func stateSystemsOf[S comparable](ecs *ECS) *stateSystems[S] {
+	for _, machine := range ecs.stateMachines {
+		if systems, ok := machine.(*stateSystems[S]); ok {
+			return systems
+		}
+	}
+	systems := &stateSystems[S]{
+		enter: make(map[S][]*SystemConfig),
+		exit:  make(map[S][]*SystemConfig),
+	}
+	ecs.stateMachines = append(ecs.stateMachines, systems)
+	return systems
+}
+
+// ### TRANSITIONS ###
+
+func (ecs *ECS) applyTransitions() error {
+	ecs.scheduleMutex.Lock()
+	machines := ecs.stateMachines
+	ecs.scheduleMutex.Unlock()
+	for _, machine := range machines {
+		if err := machine.transition(ecs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transition applies at most one queued transition, so a transition
+// requested by an OnEnter system waits for the next frame.
+func (s *stateSystems[S]) transition(ecs *ECS) error {
+	if s.state == nil {
+		return nil
+	}
+	if !s.entered {
+		s.entered = true
+		if err := ecs.runTransitionSystems(s.enter[s.state.Get()]); err != nil {
+			return err
+		}
+	}
+	s.state.mutex.Lock()
+	queued, current, next := s.state.queued, s.state.current, s.state.next
+	s.state.queued = false
+	s.state.mutex.Unlock()
+	if !queued || next == current {
+		return nil
+	}
+	if err := ecs.runTransitionSystems(s.exit[current]); err != nil {
+		return err
+	}
+	NewQuery1[*StateScoped[S]](ecs).Each(func(entity Entity, scoped *StateScoped[S]) {
+		if scoped.State == current {
+			ecs.DestroyRecursive(entity)
+		}
+	})
+	s.state.mutex.Lock()
+	s.state.current = next
+	s.state.mutex.Unlock()
+	return ecs.runTransitionSystems(s.enter[next])
+}
+
+// runTransitionSystems runs the systems one at a time on the calling
+// goroutine, applying each system's commands before the next one starts.
+func (ecs *ECS) runTransitionSystems(systems []*SystemConfig) error {
+	for _, system := range systems {
+		b, err := ecs.runnable("Transition", batch{system})
+		if err != nil {
+			return err
+		}
+		if len(b) == 0 {
+			continue
+		}
+		commands, errs := ecs.runBatch(b, 1)
+		if err := ecs.handleFailures("Transition", b, commands, errs); err != nil {
+			return err
+		}
+		for _, c := range commands {
+			ecs.ApplyCommands(c)
+		}
+	}
+	return nil
+}
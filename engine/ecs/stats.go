@@ -0,0 +1,130 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const timingWindow = 120
+
+type SystemStats struct {
+	Stage   string
+	Label   string
+	Runs    uint64
+	Last    time.Duration
+	Average time.Duration
+	P50     time.Duration
+	P95     time.Duration
+	P99     time.Duration
+}
+
+type Stats struct {
+	Frame             uint64
+	Entities          int
+	Components        int
+	Archetypes        int
+	StructuralChanges uint64
+	Systems           []SystemStats
+}
+
+type timings struct {
+	samples [timingWindow]time.Duration
+	count   int
+	next    int
+	runs    uint64
+	mutex   sync.Mutex
+}
+
+func (*Stats) Type() reflect.Type {
+	return reflect.TypeOf(Stats{})
+}
+
+// ### RECORDING ###
+
+func (t *timings) record(d time.Duration) {
+	t.mutex.Lock()
+	t.samples[t.next] = d
+	t.next = (t.next + 1) % timingWindow
+	t.count = min(t.count+1, timingWindow)
+	t.runs++
+	t.mutex.Unlock()
+}
+
+func (t *timings) stats() SystemStats {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	result := SystemStats{Runs: t.runs}
+	if t.count == 0 {
+		return result
+	}
+	result.Last = t.samples[(t.next+timingWindow-1)%timingWindow]
+	sorted := slices.Clone(t.samples[:t.count])
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	result.Average = total / time.Duration(t.count)
+	result.P50 = percentile(sorted, 50)
+	result.P95 = percentile(sorted, 95)
+	result.P99 = percentile(sorted, 99)
+	return result
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	return sorted[(len(sorted)-1)*p/100]
+}
+
+// collectStats refreshes the Stats resource at the end of a frame.
+func (ecs *ECS) collectStats() {
+	ecs.mutex.RLock()
+	ecs.stats.Frame = ecs.clock.time.Frame
+	ecs.stats.Entities = 0
+	ecs.stats.Components = 0
+	ecs.stats.Archetypes = len(ecs.archetypes)
+	for _, a := range ecs.archetypes {
+		ecs.stats.Entities += len(a.entities)
+		ecs.stats.Components += len(a.entities) * len(a.types)
+	}
+	ecs.mutex.RUnlock()
+	ecs.stats.StructuralChanges = ecs.structuralChanges.Swap(0)
+	ecs.stats.Systems = ecs.stats.Systems[:0]
+	ecs.scheduleMutex.Lock()
+	for _, sequence := range [][]uint{ecs.startupStages, ecs.frameStages, ecs.shutdownStages} {
+		for _, stage := range sequence {
+			s := ecs.stages[stage]
+			for _, system := range s.systems {
+				stats := system.timings.stats()
+				stats.Stage = s.name
+				stats.Label = system.displayName()
+				ecs.stats.Systems = append(ecs.stats.Systems, stats)
+			}
+		}
+	}
+	ecs.scheduleMutex.Unlock()
+}
+
+// ### REPORTING ###
+
+func (s *Stats) Report() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Frame %d: %d entities, %d components, %d archetypes, %d structural changes\n",
+		s.Frame, s.Entities, s.Components, s.Archetypes, s.StructuralChanges)
+	w := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Stage\tSystem\tRuns\tLast\tAverage\tP50\tP95\tP99")
+	for _, system := range s.Systems {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%v\t%v\t%v\t%v\t%v\n",
+			system.Stage, system.Label, system.Runs, system.Last, system.Average, system.P50, system.P95, system.P99)
+	}
+	w.Flush()
+	return builder.String()
+}
+
+func (ecs *ECS) Report() string {
+	return ecs.stats.Report()
+}
@@ -0,0 +1,120 @@
+package ecs
+
+import (
+	"reflect"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type Transform2D struct {
+	Position mgl32.Vec2
+	Rotation float32
+	Scale    mgl32.Vec2
+}
+
+type GlobalTransform2D struct {
+	Matrix mgl32.Mat3
+}
+
+type Transform3D struct {
+	Matrix mgl32.Mat4
+}
+
+type GlobalTransform3D struct {
+	Matrix mgl32.Mat4
+}
+
+func NewTransform2D(position mgl32.Vec2) *Transform2D {
+	return &Transform2D{Position: position, Scale: mgl32.Vec2{1, 1}}
+}
+
+func NewTransform3D(position mgl32.Vec3) *Transform3D {
+	return &Transform3D{Matrix: mgl32.Translate3D(position[0], position[1], position[2])}
+}
+
+func (*Transform2D) Type() reflect.Type {
+	return reflect.TypeOf(Transform2D{})
+}
+
+func (*GlobalTransform2D) Type() reflect.Type {
+	return reflect.TypeOf(GlobalTransform2D{})
+}
+
+func (*Transform3D) Type() reflect.Type {
+	return reflect.TypeOf(Transform3D{})
+}
+
+func (*GlobalTransform3D) Type() reflect.Type {
+	return reflect.TypeOf(GlobalTransform3D{})
+}
+
+func (t *Transform2D) Matrix() mgl32.Mat3 {
+	return mgl32.Translate2D(t.Position[0], t.Position[1]).
+		Mul3(mgl32.HomogRotate2D(t.Rotation)).
+		Mul3(mgl32.Scale2D(t.Scale[0], t.Scale[1]))
+}
+
+func (t *GlobalTransform2D) Position() mgl32.Vec2 {
+	return t.Matrix.Col(2).Vec2()
+}
+
+func (t *GlobalTransform3D) Position() mgl32.Vec3 {
+	return t.Matrix.Col(3).Vec3()
+}
+
+// ### PROPAGATION ###
+
+func (ecs *ECS) RegisterTransformPropagation() *SystemConfig {
+	return ecs.RegisterSystem(PropagateTransforms, StagePostUpdate).
+		Label("transform_propagation").
+		Reads(ComponentType[*Transform2D](), ComponentType[*Transform3D](), ComponentType[*Parent](), ComponentType[*Children]()).
+		Writes(ComponentType[*GlobalTransform2D](), ComponentType[*GlobalTransform3D]())
+}
+
+// PropagateTransforms walks each hierarchy from its roots and computes global
+// transforms from local ones, adding the global component where it's missing.
+// Entities without a local transform pass their parent's through unchanged.
+func PropagateTransforms(ecs *ECS, commands *Commands) {
+	locals2D := NewQuery1[*Transform2D](ecs)
+	globals2D := NewQuery1[*GlobalTransform2D](ecs)
+	locals3D := NewQuery1[*Transform3D](ecs)
+	globals3D := NewQuery1[*GlobalTransform3D](ecs)
+	children := NewQuery1[*Children](ecs)
+	var visit func(entity Entity, parent2D mgl32.Mat3, parent3D mgl32.Mat4)
+	visit = func(entity Entity, parent2D mgl32.Mat3, parent3D mgl32.Mat4) {
+		global2D, global3D := parent2D, parent3D
+		if local, found := locals2D.Get(entity); found {
+			global2D = parent2D.Mul3(local.Matrix())
+			if global, found := globals2D.Get(entity); found {
+				if global.Matrix != global2D {
+					global.Matrix = global2D
+					ecs.MarkChanged(entity, ComponentType[*GlobalTransform2D]())
+				}
+			} else {
+				commands.AddComponent(entity, &GlobalTransform2D{Matrix: global2D})
+			}
+		}
+		if local, found := locals3D.Get(entity); found {
+			global3D = parent3D.Mul4(local.Matrix)
+			if global, found := globals3D.Get(entity); found {
+				if global.Matrix != global3D {
+					global.Matrix = global3D
+					ecs.MarkChanged(entity, ComponentType[*GlobalTransform3D]())
+				}
+			} else {
+				commands.AddComponent(entity, &GlobalTransform3D{Matrix: global3D})
+			}
+		}
+		if c, found := children.Get(entity); found {
+			for _, child := range c.Entities {
+				visit(child, global2D, global3D)
+			}
+		}
+	}
+	roots, _ := ecs.EntityQuery(nil, []reflect.Type{ComponentType[*Parent]()})
+	for _, root := range roots {
+		if ecs.HasComponent(root, ComponentType[*Transform2D]()) || ecs.HasComponent(root, ComponentType[*Transform3D]()) || ecs.HasComponent(root, ComponentType[*Children]()) {
+			visit(root, mgl32.Ident3(), mgl32.Ident4())
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+)
+
+// ### ENTITY TRANSFER ###
+
+// Transfer moves entities and their components from this world into dst,
+// returning the new entity for each one moved. Entity references inside the
+// moved components are remapped; references to entities that stay behind
+// become the zero entity, and such Parent, Children and Pair links are
+// dropped. Remove hooks run in this world and add hooks in dst. Neither
+// world should be executing systems during the transfer.
+func (ecs *ECS) Transfer(dst *ECS, entities ...Entity) map[Entity]Entity {
+	mapping := make(map[Entity]Entity, len(entities))
+	moved := make(map[Entity][]Component, len(entities))
+	for _, entity := range entities {
+		if _, found := mapping[entity]; found {
+			continue
+		}
+		components, found := ecs.GetComponents(entity)
+		if !found {
+			continue
+		}
+		// copies, so this world's remove hooks can't touch what dst gets
+		for i, component := range components {
+			components[i] = cloneComponent(component)
+		}
+		moved[entity] = components
+		mapping[entity] = dst.CreateEntity()
+	}
+	for _, entity := range entities {
+		if _, found := moved[entity]; found {
+			ecs.DestroyEntity(entity)
+		}
+	}
+	for _, entity := range entities {
+		components, found := moved[entity]
+		if !found {
+			continue
+		}
+		delete(moved, entity)
+		for _, component := range components {
+			component = remapComponent(component, mapping)
+			if keepTransferred(component) {
+				dst.AddComponent(mapping[entity], component)
+			}
+		}
+	}
+	return mapping
+}
+
+// Merge moves every entity of src into this world, such as a level built
+// in the background, and returns the mapping from old to new entities.
+func (ecs *ECS) Merge(src *ECS) map[Entity]Entity {
+	entities, _ := src.EntityQuery(nil, nil)
+	slices.SortFunc(entities, func(a, b Entity) int {
+		return int(a.Index()) - int(b.Index())
+	})
+	return src.Transfer(ecs, entities...)
+}
+
+func remapComponent(component Component, mapping map[Entity]Entity) Component {
+	v := reflect.ValueOf(component)
+	if v.Kind() == reflect.Pointer {
+		remapEntities(v, mapping)
+		return component
+	}
+	copied := reflect.New(v.Type())
+	copied.Elem().Set(v)
+	remapEntities(copied, mapping)
+	return copied.Elem().Interface().(Component)
+}
+
+// keepTransferred drops links to entities that weren't transferred, and
+// reports whether anything is left of the component.
+func keepTransferred(component Component) bool {
+	isZero := func(e Entity) bool { return e == 0 }
+	switch c := component.(type) {
+	case *Parent:
+		return c.Entity != 0
+	case *Children:
+		c.Entities = slices.DeleteFunc(c.Entities, isZero)
+		return len(c.Entities) > 0
+	case pair:
+		for c.removeTarget(0) {
+		}
+		return len(c.targets()) > 0
+	}
+	return true
+}
+
+// ### SHARED RESOURCES ###
+
+// ShareResource makes the resource of type T in src readable from dst too.
+// Both worlds see the same value, which dst can't get through ResourceMut.
+// src must not write it while dst's systems may be reading it.
+func ShareResource[T any](dst, src *ECS) bool {
+	t := reflect.TypeFor[T]()
+	src.resourceMutex.RLock()
+	value, found := src.resources[t]
+	src.resourceMutex.RUnlock()
+	if !found {
+		return false
+	}
+	dst.resourceMutex.Lock()
+	dst.resources[t] = value
+	dst.shared[t] = true
+	dst.resourceMutex.Unlock()
+	return true
+}
@@ -0,0 +1,13 @@
+package global
+
+import "github.com/laranc/monorepo/engine/ecs"
+
+// Plugin refreshes State's time, keyboard and mouse at the start of every
+// frame.
+type Plugin struct{}
+
+func (Plugin) Build(world *ecs.ECS) {
+	world.RegisterSystem(func(world *ecs.ECS, commands *ecs.Commands) {
+		UpdateAll()
+	}, ecs.StagePreUpdate).Label("global")
+}
@@ -0,0 +1,28 @@
+package graphics2d
+
+import (
+	"reflect"
+
+	"github.com/laranc/monorepo/engine/ecs"
+)
+
+// AnimationPlugin stores an AnimationHandler resource and advances its
+// animations every frame, in milliseconds to match global.State.Time.
+type AnimationPlugin struct{}
+
+func (AnimationPlugin) Build(world *ecs.ECS) {
+	ecs.InsertResource(world, MakeAnimationHandler())
+	world.RegisterSystem(func(world *ecs.ECS, commands *ecs.Commands) {
+		handler, err := ecs.ResourceMut[AnimationHandler](world, commands)
+		if err != nil {
+			return
+		}
+		time, err := ecs.ResourceOf[ecs.Time](world, commands)
+		if err != nil {
+			return
+		}
+		handler.AnimationUpdate(float32(time.Delta.Seconds() * 1000))
+	}, ecs.StageUpdate).Label("animation").
+		Reads(reflect.TypeFor[ecs.Time]()).
+		Writes(reflect.TypeFor[AnimationHandler]())
+}
@@ -0,0 +1,359 @@
+package physics2d
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/laranc/monorepo/engine/ecs"
+)
+
+// RigidBody gives an entity a moving body, created at its Transform2D
+// position. After each physics step the body's position is written back to
+// the Transform2D and its velocity to Velocity. Changing Velocity only
+// reaches the body when the component is replaced or marked changed, and a
+// replacing RigidBody keeps the body, taking on its new Size and settings.
+type RigidBody struct {
+	Size           mgl32.Vec2
+	Velocity       mgl32.Vec2
+	CollisionLayer uint8
+	CollisionMask  uint8
+	Kinematic      bool
+	body           uint64
+}
+
+// Collider gives an entity a static body that blocks rigid bodies.
+type Collider struct {
+	Size           mgl32.Vec2
+	CollisionLayer uint8
+	body           uint64
+}
+
+// Trigger gives an entity a body that follows its Transform2D and reports
+// overlaps as Collision events without blocking anything.
+type Trigger struct {
+	Size           mgl32.Vec2
+	CollisionLayer uint8
+	CollisionMask  uint8
+	body           uint64
+}
+
+// Collision is sent as an ECS event whenever a body's OnHit fires. Other is
+// the zero entity if it isn't known to the world.
+type Collision struct {
+	Entity  ecs.Entity
+	Other   ecs.Entity
+	Normal  mgl32.Vec2
+	Static  bool
+	Trigger bool
+}
+
+// bodyMap links bodies in a PhysicsState to the entities that own them.
+// Hooks can fire from any system, so every access goes through the mutex.
+type bodyMap struct {
+	state          *PhysicsState
+	events         *ecs.Events[Collision]
+	bodies         map[uint64]ecs.Entity
+	statics        map[uint64]ecs.Entity
+	triggers       map[uint64]bool
+	pushTransforms *ecs.Query2[*RigidBody, *ecs.Transform2D]
+	pushVelocities *ecs.Query1[*RigidBody]
+	pushTriggers   *ecs.Query2[*Trigger, *ecs.Transform2D]
+	pull           *ecs.Query2[*RigidBody, *ecs.Transform2D]
+	mutex          sync.Mutex
+}
+
+func (*RigidBody) Type() reflect.Type {
+	return reflect.TypeOf(RigidBody{})
+}
+
+func (*Collider) Type() reflect.Type {
+	return reflect.TypeOf(Collider{})
+}
+
+func (*Trigger) Type() reflect.Type {
+	return reflect.TypeOf(Trigger{})
+}
+
+func newBodyMap(world *ecs.ECS, state *PhysicsState, events *ecs.Events[Collision]) *bodyMap {
+	return &bodyMap{
+		state:          state,
+		events:         events,
+		bodies:         make(map[uint64]ecs.Entity),
+		statics:        make(map[uint64]ecs.Entity),
+		triggers:       make(map[uint64]bool),
+		pushTransforms: ecs.NewQuery2[*RigidBody, *ecs.Transform2D](world).Filter(ecs.Changed[*ecs.Transform2D]{}),
+		pushVelocities: ecs.NewQuery1[*RigidBody](world).Filter(ecs.Changed[*RigidBody]{}),
+		pushTriggers:   ecs.NewQuery2[*Trigger, *ecs.Transform2D](world).Filter(ecs.Changed[*ecs.Transform2D]{}),
+		pull:           ecs.NewQuery2[*RigidBody, *ecs.Transform2D](world),
+	}
+}
+
+// ### HOOKS ###
+
+func (m *bodyMap) registerHooks(world *ecs.ECS) {
+	world.OnAdd(ecs.ComponentType[*RigidBody](), func(world *ecs.ECS, entity ecs.Entity, component ecs.Component) {
+		position := transformPosition(world, entity)
+		m.mutex.Lock()
+		m.createRigidBody(entity, component.(*RigidBody), position)
+		m.mutex.Unlock()
+	})
+	world.OnAdd(ecs.ComponentType[*Collider](), func(world *ecs.ECS, entity ecs.Entity, component ecs.Component) {
+		position := transformPosition(world, entity)
+		m.mutex.Lock()
+		m.createCollider(entity, component.(*Collider), position)
+		m.mutex.Unlock()
+	})
+	world.OnAdd(ecs.ComponentType[*Trigger](), func(world *ecs.ECS, entity ecs.Entity, component ecs.Component) {
+		position := transformPosition(world, entity)
+		m.mutex.Lock()
+		m.createTrigger(entity, component.(*Trigger), position)
+		m.mutex.Unlock()
+	})
+	world.OnRemove(ecs.ComponentType[*RigidBody](), func(world *ecs.ECS, entity ecs.Entity, component ecs.Component) {
+		m.mutex.Lock()
+		m.destroyBody(entity, component.(*RigidBody).body)
+		m.mutex.Unlock()
+	})
+	world.OnRemove(ecs.ComponentType[*Trigger](), func(world *ecs.ECS, entity ecs.Entity, component ecs.Component) {
+		m.mutex.Lock()
+		m.destroyBody(entity, component.(*Trigger).body)
+		m.mutex.Unlock()
+	})
+	world.OnRemove(ecs.ComponentType[*Collider](), func(world *ecs.ECS, entity ecs.Entity, component ecs.Component) {
+		m.mutex.Lock()
+		m.destroyStaticBody(entity, component.(*Collider).body)
+		m.mutex.Unlock()
+	})
+	// a replacing component keeps the body of the one it replaces
+	world.OnReplace(ecs.ComponentType[*RigidBody](), func(world *ecs.ECS, entity ecs.Entity, component ecs.Component) {
+		old := component.(*RigidBody)
+		rigidBody, found := ecs.NewQuery1[*RigidBody](world).Get(entity)
+		position := transformPosition(world, entity)
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		if !found {
+			m.destroyBody(entity, old.body)
+			return
+		}
+		body := m.state.GetBody(old.body)
+		if !m.ownsBody(entity, old.body) || body == nil {
+			m.createRigidBody(entity, rigidBody, position)
+			return
+		}
+		rigidBody.body = old.body
+		body.aabb.halfSize = mgl32.Vec2{rigidBody.Size[0] / 2, rigidBody.Size[1] / 2}
+		body.velocity = rigidBody.Velocity
+		body.collisionLayer = rigidBody.CollisionLayer
+		body.collisionMask = rigidBody.CollisionMask
+		body.isKinematic = rigidBody.Kinematic
+	})
+	world.OnReplace(ecs.ComponentType[*Collider](), func(world *ecs.ECS, entity ecs.Entity, component ecs.Component) {
+		old := component.(*Collider)
+		collider, found := ecs.NewQuery1[*Collider](world).Get(entity)
+		position := transformPosition(world, entity)
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		if !found {
+			m.destroyStaticBody(entity, old.body)
+			return
+		}
+		staticBody := m.state.GetStaticBody(old.body)
+		if !m.ownsStaticBody(entity, old.body) || staticBody == nil {
+			m.createCollider(entity, collider, position)
+			return
+		}
+		collider.body = old.body
+		staticBody.aabb.halfSize = mgl32.Vec2{collider.Size[0] / 2, collider.Size[1] / 2}
+		staticBody.collisionLayer = collider.CollisionLayer
+	})
+	world.OnReplace(ecs.ComponentType[*Trigger](), func(world *ecs.ECS, entity ecs.Entity, component ecs.Component) {
+		old := component.(*Trigger)
+		trigger, found := ecs.NewQuery1[*Trigger](world).Get(entity)
+		position := transformPosition(world, entity)
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		if !found {
+			m.destroyBody(entity, old.body)
+			return
+		}
+		body := m.state.GetBody(old.body)
+		if !m.ownsBody(entity, old.body) || body == nil {
+			m.createTrigger(entity, trigger, position)
+			return
+		}
+		trigger.body = old.body
+		body.aabb.halfSize = mgl32.Vec2{trigger.Size[0] / 2, trigger.Size[1] / 2}
+		body.collisionLayer = trigger.CollisionLayer
+		body.collisionMask = trigger.CollisionMask
+	})
+}
+
+// ### BODIES ###
+
+// The rest of the bodyMap methods expect the mutex to be held.
+
+func (m *bodyMap) createRigidBody(entity ecs.Entity, rigidBody *RigidBody, position mgl32.Vec2) {
+	rigidBody.body = m.state.CreateBody(position, rigidBody.Size, rigidBody.Velocity, rigidBody.CollisionLayer, rigidBody.CollisionMask, m.onHit, m.onHitStatic, rigidBody.Kinematic, true)
+	m.bodies[rigidBody.body] = entity
+	delete(m.triggers, rigidBody.body)
+}
+
+func (m *bodyMap) createCollider(entity ecs.Entity, collider *Collider, position mgl32.Vec2) {
+	collider.body = m.state.CreateStaticBody(position, collider.Size, collider.CollisionLayer)
+	m.statics[collider.body] = entity
+}
+
+func (m *bodyMap) createTrigger(entity ecs.Entity, trigger *Trigger, position mgl32.Vec2) {
+	trigger.body = m.state.CreateTrigger(position, trigger.Size, trigger.CollisionLayer, trigger.CollisionMask, m.onHit)
+	m.bodies[trigger.body] = entity
+	m.triggers[trigger.body] = true
+}
+
+// rigidBodyOf and triggerOf return the entity's body, creating one at
+// position if the component doesn't own a body yet.
+func (m *bodyMap) rigidBodyOf(entity ecs.Entity, rigidBody *RigidBody, position mgl32.Vec2) *Body {
+	if !m.ownsBody(entity, rigidBody.body) || m.triggers[rigidBody.body] {
+		m.createRigidBody(entity, rigidBody, position)
+	}
+	return m.state.GetBody(rigidBody.body)
+}
+
+func (m *bodyMap) triggerOf(entity ecs.Entity, trigger *Trigger, position mgl32.Vec2) *Body {
+	if !m.ownsBody(entity, trigger.body) || !m.triggers[trigger.body] {
+		m.createTrigger(entity, trigger, position)
+	}
+	return m.state.GetBody(trigger.body)
+}
+
+// rebuild throws every body away and makes new ones from the components,
+// for components that never went through the hooks: those added before the
+// plugin was built, and those brought back by ECS.Restore.
+func (m *bodyMap) rebuild(world *ecs.ECS) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for id := range m.bodies {
+		m.state.DestroyBody(id)
+	}
+	for id := range m.statics {
+		m.state.DestroyStaticBody(id)
+	}
+	clear(m.bodies)
+	clear(m.statics)
+	clear(m.triggers)
+	ecs.NewQuery1[*RigidBody](world).Each(func(entity ecs.Entity, rigidBody *RigidBody) {
+		m.createRigidBody(entity, rigidBody, transformPosition(world, entity))
+	})
+	ecs.NewQuery1[*Collider](world).Each(func(entity ecs.Entity, collider *Collider) {
+		m.createCollider(entity, collider, transformPosition(world, entity))
+	})
+	ecs.NewQuery1[*Trigger](world).Each(func(entity ecs.Entity, trigger *Trigger) {
+		m.createTrigger(entity, trigger, transformPosition(world, entity))
+	})
+}
+
+// ownsBody reports whether id is a body made for entity. A component added
+// before the plugin was built has the zero id without owning body 0.
+func (m *bodyMap) ownsBody(entity ecs.Entity, id uint64) bool {
+	owner, found := m.bodies[id]
+	return found && owner == entity
+}
+
+func (m *bodyMap) ownsStaticBody(entity ecs.Entity, id uint64) bool {
+	owner, found := m.statics[id]
+	return found && owner == entity
+}
+
+func (m *bodyMap) destroyBody(entity ecs.Entity, id uint64) {
+	if !m.ownsBody(entity, id) {
+		return
+	}
+	m.state.DestroyBody(id)
+	delete(m.bodies, id)
+	delete(m.triggers, id)
+}
+
+func (m *bodyMap) destroyStaticBody(entity ecs.Entity, id uint64) {
+	if !m.ownsStaticBody(entity, id) {
+		return
+	}
+	m.state.DestroyStaticBody(id)
+	delete(m.statics, id)
+}
+
+func transformPosition(world *ecs.ECS, entity ecs.Entity) mgl32.Vec2 {
+	if transform, found := ecs.NewQuery1[*ecs.Transform2D](world).Get(entity); found {
+		return transform.Position
+	}
+	return mgl32.Vec2{}
+}
+
+// ### COLLISION EVENTS ###
+
+// onHit and onHitStatic run inside Step, which holds the mutex.
+
+func (m *bodyMap) onHit(self *Body, other *Body, hit Hit) {
+	entity, found := m.bodies[self.self]
+	if !found || self == other {
+		return
+	}
+	otherEntity, found := m.bodies[other.self]
+	if !found {
+		// bodies stay in the state after their entity is gone
+		return
+	}
+	m.events.Send(Collision{
+		Entity:  entity,
+		Other:   otherEntity,
+		Normal:  hit.normal,
+		Trigger: m.triggers[self.self] || m.triggers[other.self],
+	})
+}
+
+func (m *bodyMap) onHitStatic(self *Body, other *StaticBody, hit Hit) {
+	entity, found := m.bodies[self.self]
+	if !found {
+		return
+	}
+	m.events.Send(Collision{
+		Entity: entity,
+		Other:  m.statics[other.self],
+		Normal: hit.normal,
+		Static: true,
+	})
+}
+
+// ### STEP ###
+
+// step pushes changed transforms and velocities into the bodies, advances
+// the simulation by one fixed step and writes the results back.
+func (m *bodyMap) step(world *ecs.ECS, commands *ecs.Commands) {
+	time, err := ecs.ResourceOf[ecs.Time](world, commands)
+	if err != nil {
+		return
+	}
+	m.mutex.Lock()
+	m.pushTransforms.Each(func(entity ecs.Entity, rigidBody *RigidBody, transform *ecs.Transform2D) {
+		m.rigidBodyOf(entity, rigidBody, transform.Position).SetPosition(transform.Position)
+	})
+	m.pushVelocities.Each(func(entity ecs.Entity, rigidBody *RigidBody) {
+		m.rigidBodyOf(entity, rigidBody, transformPosition(world, entity)).SetVelocity(rigidBody.Velocity)
+	})
+	m.pushTriggers.Each(func(entity ecs.Entity, trigger *Trigger, transform *ecs.Transform2D) {
+		m.triggerOf(entity, trigger, transform.Position).SetPosition(transform.Position)
+	})
+	m.state.Step(float32(time.FixedDelta.Seconds() * 1000))
+	moved := make([]ecs.Entity, 0)
+	m.pull.Each(func(entity ecs.Entity, rigidBody *RigidBody, transform *ecs.Transform2D) {
+		body := m.rigidBodyOf(entity, rigidBody, transform.Position)
+		rigidBody.Velocity = body.Velocity()
+		if transform.Position != body.Position() {
+			transform.Position = body.Position()
+			moved = append(moved, entity)
+		}
+	})
+	m.mutex.Unlock()
+	for _, entity := range moved {
+		world.MarkChanged(entity, ecs.ComponentType[*ecs.Transform2D]())
+	}
+}
@@ -32,6 +32,7 @@ type Body struct {
 type StaticBody struct {
 	aabb           AABB
 	collisionLayer uint8
+	isActive       bool
 	self           uint64
 }
 
@@ -65,6 +66,11 @@ func MakePhysicsState() PhysicsState {
 }
 
 func (state *PhysicsState) Update() {
+	state.Step(global.State.Time.Delta)
+}
+
+// Step advances every active body by dt milliseconds.
+func (state *PhysicsState) Step(dt float32) {
 	for _, body := range state.bodies {
 		if !body.isActive {
 			continue
@@ -76,7 +82,7 @@ func (state *PhysicsState) Update() {
 			}
 		}
 		body.velocity = body.velocity.Add(body.acceleration)
-		scaledVelocity := body.velocity.Mul(float32(global.State.Time.Delta) * tickRate)
+		scaledVelocity := body.velocity.Mul(dt * tickRate)
 		for range iterations {
 			state.sweepResponse(body, scaledVelocity)
 			state.stationaryResponse(body)
@@ -123,6 +129,7 @@ func (state *PhysicsState) CreateStaticBody(position, size mgl32.Vec2, collision
 			halfSize: mgl32.Vec2{size[0] / 2, size[1] / 2},
 		},
 		collisionLayer: collisionLayer,
+		isActive:       true,
 		self:           id,
 	}
 	state.staticBodies = append(state.staticBodies, staticBody)
@@ -134,26 +141,61 @@ func (state *PhysicsState) CreateTrigger(position, size mgl32.Vec2, collisionLay
 }
 
 func (state *PhysicsState) DestroyBody(id uint64) {
-	body := state.GetBody(id)
-	body.isActive = false
+	if body := state.GetBody(id); body != nil {
+		body.isActive = false
+	}
+}
+
+func (state *PhysicsState) DestroyStaticBody(id uint64) {
+	if staticBody := state.GetStaticBody(id); staticBody != nil {
+		staticBody.isActive = false
+	}
 }
 
 // Getters
 
 func (state *PhysicsState) GetBody(id uint64) *Body {
-	if id <= uint64(len(state.bodies)) {
+	if id < uint64(len(state.bodies)) {
 		return state.bodies[id]
 	}
 	return nil
 }
 
 func (state *PhysicsState) GetStaticBody(id uint64) *StaticBody {
-	if id <= uint64(len(state.staticBodies)) {
+	if id < uint64(len(state.staticBodies)) {
 		return state.staticBodies[id]
 	}
 	return nil
 }
 
+func (body *Body) ID() uint64 {
+	return body.self
+}
+
+func (body *Body) Position() mgl32.Vec2 {
+	return body.aabb.position
+}
+
+func (body *Body) SetPosition(position mgl32.Vec2) {
+	body.aabb.position = position
+}
+
+func (body *Body) Velocity() mgl32.Vec2 {
+	return body.velocity
+}
+
+func (body *Body) SetVelocity(velocity mgl32.Vec2) {
+	body.velocity = velocity
+}
+
+func (staticBody *StaticBody) ID() uint64 {
+	return staticBody.self
+}
+
+func (hit Hit) Normal() mgl32.Vec2 {
+	return hit.normal
+}
+
 func (state *PhysicsState) BodyCount() uint64 {
 	return uint64(len(state.bodies))
 }
@@ -286,7 +328,7 @@ func (state *PhysicsState) updateSweepResult(result *Hit, body *Body, otherID ui
 
 func (state *PhysicsState) updateSweeResultStatic(result *Hit, body *Body, otherID uint64, velocity mgl32.Vec2) {
 	other := state.GetStaticBody(otherID)
-	if (body.collisionMask & other.collisionLayer) == 0 {
+	if !other.isActive || (body.collisionMask&other.collisionLayer) == 0 {
 		return
 	}
 	sum := other.aabb
@@ -317,7 +359,7 @@ func (state *PhysicsState) sweepBodies(body *Body, velocity mgl32.Vec2) Hit {
 
 func (state *PhysicsState) sweepStaticBodies(body *Body, velocity mgl32.Vec2) Hit {
 	result := Hit{time: math.Inf(1)}
-	for i := range state.bodies {
+	for i := range state.staticBodies {
 		state.updateSweeResultStatic(&result, body, uint64(i), velocity)
 	}
 	return result
@@ -343,12 +385,15 @@ func (state *PhysicsState) sweepResponse(body *Body, velocity mgl32.Vec2) {
 			body.onHitStatic(body, state.GetStaticBody(hit.other), hit)
 		}
 	} else {
-		body.aabb.position.Add(velocity)
+		body.aabb.position = body.aabb.position.Add(velocity)
 	}
 }
 
 func (state *PhysicsState) stationaryResponse(body *Body) {
 	for _, staticBody := range state.staticBodies {
+		if !staticBody.isActive {
+			continue
+		}
 		aabb := AABBMinkowskiDifference(staticBody.aabb, body.aabb)
 		min, max := AABBMinMax(aabb)
 		if min[0] <= 0 && max[0] >= 0 && min[1] <= 0 && max[1] >= 0 {
@@ -0,0 +1,34 @@
+package physics2d
+
+import (
+	"reflect"
+
+	"github.com/laranc/monorepo/engine/ecs"
+)
+
+// Plugin stores a PhysicsState resource, creates bodies for RigidBody,
+// Collider and Trigger components and steps the simulation in fixed update,
+// sending a Collision event for every hit.
+type Plugin struct{}
+
+func (Plugin) Build(world *ecs.ECS) {
+	ecs.InsertResource(world, MakePhysicsState())
+	state, err := ecs.ResourceMut[PhysicsState](world, nil)
+	if err != nil {
+		return
+	}
+	events := ecs.NewEvents[Collision]()
+	world.RegisterResource(events)
+	bodies := newBodyMap(world, state, events)
+	bodies.registerHooks(world)
+	bodies.rebuild(world)
+	world.OnRestore(bodies.rebuild)
+	world.RegisterSystem(bodies.step, ecs.StageFixedUpdate).Label("physics2d").
+		Reads(reflect.TypeFor[ecs.Time](), ecs.ComponentType[*Trigger]()).
+		Writes(
+			reflect.TypeFor[PhysicsState](),
+			reflect.TypeFor[ecs.Events[Collision]](),
+			ecs.ComponentType[*RigidBody](),
+			ecs.ComponentType[*ecs.Transform2D](),
+		)
+}